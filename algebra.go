@@ -0,0 +1,104 @@
+package jsonchamp
+
+// ValueSide selects which map's value is kept for a key that exists in both maps.
+type ValueSide int
+
+const (
+	// ValuesFromReceiver keeps the value of the map the method is called on.
+	ValuesFromReceiver ValueSide = iota
+	// ValuesFromOther keeps the value of the other map.
+	ValuesFromOther
+)
+
+type algebraOptions struct {
+	side ValueSide
+}
+
+// AlgebraOption is a function that sets an option on Intersect.
+type AlgebraOption func(*algebraOptions)
+
+// WithValuesFrom sets which map's value is kept for keys that exist in both maps.
+// By default the value of the receiver is kept.
+func WithValuesFrom(side ValueSide) AlgebraOption {
+	return func(o *algebraOptions) {
+		o.side = side
+	}
+}
+
+func (o algebraOptions) resolver() entryResolver {
+	if o.side == ValuesFromOther {
+		return func(_ *value, b *value) *value { return b }
+	}
+
+	return keepLeft
+}
+
+// Intersect returns a map with the keys that exist in both maps.
+// When both maps use the same hasher, the tries are combined node by node and sub trees shared by both
+// maps are reused as they are. The returned map uses the hasher of the receiver.
+func (m *Map) Intersect(other *Map, opts ...AlgebraOption) *Map {
+	options := algebraOptions{side: ValuesFromReceiver}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	resolve := options.resolver()
+
+	if sameHasher(m.hasher, other.hasher) {
+		return &Map{root: intersectNodes(m.root, other.root, resolve), hasher: m.hasher, interner: m.interner}
+	}
+
+	root := newRootNode()
+
+	m.root.all(func(v *value) bool {
+		if otherValue, ok := other.root.lookup(newKey(v.key.key, other.hash(v.key.key))); ok {
+			kept := resolve(v, otherValue)
+			root = root.setValue(&value{key: v.key, value: kept.value})
+		}
+
+		return true
+	})
+
+	return &Map{root: root, hasher: m.hasher, interner: m.interner}
+}
+
+// Subtract returns a map with the keys of the receiver that do not exist in the other map.
+// Sub trees that only exist in the receiver are reused as they are.
+func (m *Map) Subtract(other *Map) *Map {
+	if sameHasher(m.hasher, other.hasher) {
+		return &Map{root: differenceNodes(m.root, other.root), hasher: m.hasher, interner: m.interner}
+	}
+
+	root := m.root
+
+	other.root.all(func(v *value) bool {
+		root, _ = root.delete(newKey(v.key.key, m.hash(v.key.key)))
+
+		return true
+	})
+
+	return &Map{root: root, hasher: m.hasher, interner: m.interner}
+}
+
+// SymmetricDifference returns a map with the keys that exist in exactly one of the maps, with their values.
+// The returned map uses the hasher of the receiver.
+func (m *Map) SymmetricDifference(other *Map) *Map {
+	if sameHasher(m.hasher, other.hasher) {
+		root := unionNodes(differenceNodes(m.root, other.root), differenceNodes(other.root, m.root), keepLeft)
+
+		return &Map{root: root, hasher: m.hasher, interner: m.interner}
+	}
+
+	root := m.Subtract(other).root
+
+	other.root.all(func(v *value) bool {
+		k := newKey(v.key.key, m.hash(v.key.key))
+		if _, ok := m.root.lookup(k); !ok {
+			root = root.setValue(&value{key: k, value: v.value})
+		}
+
+		return true
+	})
+
+	return &Map{root: root, hasher: m.hasher, interner: m.interner}
+}
@@ -0,0 +1,124 @@
+package jsonchamp
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"testing"
+)
+
+func algebraTestMaps(opts ...MapOption) (*Map, *Map) {
+	a, b := New(), New(opts...)
+
+	for i := range 1000 {
+		key := fmt.Sprintf("key-%d", i)
+
+		if i%2 == 0 {
+			a = a.Set(key, "a")
+		}
+
+		if i%3 == 0 {
+			b = b.Set(key, "b")
+		}
+	}
+
+	return a, b
+}
+
+func TestMapAlgebra(t *testing.T) {
+	t.Parallel()
+
+	hashers := []struct {
+		name string
+		opts []MapOption
+	}{
+		{name: "same hasher", opts: nil},
+		{name: "different hasher", opts: []MapOption{WithHasher(fnv.New64a)}},
+	}
+
+	for _, h := range hashers {
+		a, b := algebraTestMaps(h.opts...)
+
+		tests := []struct {
+			name string
+			got  *Map
+			want func(i int) (any, bool)
+		}{
+			{
+				name: "Intersect",
+				got:  a.Intersect(b),
+				want: func(i int) (any, bool) { return "a", i%2 == 0 && i%3 == 0 },
+			},
+			{
+				name: "Intersect values from other",
+				got:  a.Intersect(b, WithValuesFrom(ValuesFromOther)),
+				want: func(i int) (any, bool) { return "b", i%2 == 0 && i%3 == 0 },
+			},
+			{
+				name: "Subtract",
+				got:  a.Subtract(b),
+				want: func(i int) (any, bool) { return "a", i%2 == 0 && i%3 != 0 },
+			},
+			{
+				name: "SymmetricDifference",
+				got:  a.SymmetricDifference(b),
+				want: func(i int) (any, bool) {
+					if i%2 == 0 {
+						return "a", i%3 != 0
+					}
+
+					return "b", i%3 == 0
+				},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(h.name+"/"+tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				count := 0
+
+				for i := range 1000 {
+					key := fmt.Sprintf("key-%d", i)
+					wantValue, wantExists := tt.want(i)
+					gotValue, gotExists := tt.got.Get(key)
+
+					if gotExists != wantExists || (wantExists && gotValue != wantValue) {
+						t.Fatalf("%s = %v, %v, want %v, %v", key, gotValue, gotExists, wantValue, wantExists)
+					}
+
+					if wantExists {
+						count++
+					}
+				}
+
+				if tt.got.Len() != count {
+					t.Errorf("Len() = %d, want %d", tt.got.Len(), count)
+				}
+			})
+		}
+	}
+}
+
+func TestMapAlgebraSharesNodes(t *testing.T) {
+	t.Parallel()
+
+	a, _ := algebraTestMaps()
+	b := a.Set("extra", 1)
+
+	if got := a.Intersect(a); got.root != a.root {
+		t.Errorf("expected intersecting a map with itself to reuse its root")
+	}
+
+	if got := b.Subtract(a); !slices.Equal(got.Keys(), []string{"extra"}) {
+		t.Errorf("Subtract() keys = %v, want [extra]", got.Keys())
+	}
+
+	if got := a.SymmetricDifference(b); !slices.Equal(got.Keys(), []string{"extra"}) {
+		t.Errorf("SymmetricDifference() keys = %v, want [extra]", got.Keys())
+	}
+
+	if got := a.Subtract(a); got.Len() != 0 {
+		t.Errorf("expected subtracting a map from itself to be empty, got %d keys", got.Len())
+	}
+}
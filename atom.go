@@ -0,0 +1,141 @@
+package jsonchamp
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Watcher is called after the value of an Atom changes, with the old and new versions and old.Diff(new).
+// The diff is nil if it cannot be computed.
+type Watcher func(oldValue *Map, newValue *Map, diff *Map)
+
+// Atom holds the current version of a map and can be shared between goroutines.
+// Since maps are immutable, readers can use the loaded version for as long as they need,
+// while writers replace it with new versions.
+type Atom struct {
+	current atomic.Pointer[Map]
+
+	mu       sync.Mutex
+	watchers map[int]Watcher
+	nextID   int
+}
+
+// NewAtom creates an atom holding the map. A nil map is replaced by an empty map.
+func NewAtom(m *Map) *Atom {
+	if m == nil {
+		m = New()
+	}
+
+	a := &Atom{current: atomic.Pointer[Map]{}, mu: sync.Mutex{}, watchers: make(map[int]Watcher), nextID: 0}
+	a.current.Store(m)
+
+	return a
+}
+
+// Load returns the current version.
+func (a *Atom) Load() *Map {
+	return a.current.Load()
+}
+
+// Store replaces the current version. A nil map is stored as an empty map.
+func (a *Atom) Store(m *Map) {
+	if m == nil {
+		m = New()
+	}
+
+	if old := a.current.Swap(m); old != m {
+		a.notify(old, m)
+	}
+}
+
+// CompareAndSwap replaces the current version with newValue if it is still oldValue.
+// Versions are compared by identity, not by content. It returns true if the version was replaced.
+// A nil newValue is stored as an empty map.
+func (a *Atom) CompareAndSwap(oldValue *Map, newValue *Map) bool {
+	if newValue == nil {
+		newValue = New()
+	}
+
+	if !a.current.CompareAndSwap(oldValue, newValue) {
+		return false
+	}
+
+	if oldValue != newValue {
+		a.notify(oldValue, newValue)
+	}
+
+	return true
+}
+
+// Swap replaces the current version with the result of fn and returns the new version.
+// If another goroutine replaces the version while fn runs, fn is called again with the newer version,
+// so fn should not have side effects.
+func (a *Atom) Swap(fn func(*Map) *Map) *Map {
+	for {
+		oldValue := a.current.Load()
+
+		newValue := fn(oldValue)
+		if a.CompareAndSwap(oldValue, newValue) {
+			return newValue
+		}
+	}
+}
+
+// Watch registers a watcher that is called after every change of the version, and returns a function
+// that removes it. Watchers are called by the goroutine that made the change, after the change is visible
+// to Load. Concurrent changes may reach a watcher in any order.
+// A panic in a watcher is recovered, so it does not reach the writer, whose change is already visible,
+// or stop the other watchers.
+func (a *Atom) Watch(w Watcher) func() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	id := a.nextID
+	a.nextID++
+	a.watchers[id] = w
+
+	return func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		delete(a.watchers, id)
+	}
+}
+
+func (a *Atom) notify(oldValue *Map, newValue *Map) {
+	a.mu.Lock()
+	watchers := make([]Watcher, 0, len(a.watchers))
+
+	for _, w := range a.watchers {
+		watchers = append(watchers, w)
+	}
+	a.mu.Unlock()
+
+	if len(watchers) == 0 {
+		return
+	}
+
+	diff := watchDiff(oldValue, newValue)
+	for _, w := range watchers {
+		callWatcher(w, oldValue, newValue, diff)
+	}
+}
+
+// watchDiff returns the diff passed to watchers, or nil if computing it panics.
+func watchDiff(oldValue *Map, newValue *Map) (diff *Map) {
+	defer func() {
+		if recover() != nil {
+			diff = nil
+		}
+	}()
+
+	return oldValue.Diff(newValue)
+}
+
+func callWatcher(w Watcher, oldValue *Map, newValue *Map, diff *Map) {
+	defer func() {
+		_ = recover()
+	}()
+
+	w(oldValue, newValue, diff)
+}
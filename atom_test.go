@@ -0,0 +1,179 @@
+package jsonchamp
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"testing"
+)
+
+func TestAtomLoadStoreCompareAndSwap(t *testing.T) {
+	t.Parallel()
+
+	first := NewFromItems("a", 1)
+	second := first.Set("a", 2)
+
+	a := NewAtom(first)
+	if a.Load() != first {
+		t.Fatalf("expected Load to return the initial map")
+	}
+
+	if a.CompareAndSwap(second, first) {
+		t.Errorf("expected CompareAndSwap with a stale version to fail")
+	}
+
+	if !a.CompareAndSwap(first, second) || a.Load() != second {
+		t.Errorf("expected CompareAndSwap with the current version to succeed")
+	}
+
+	a.Store(nil)
+
+	if a.Load().Len() != 0 {
+		t.Errorf("expected storing nil to store an empty map")
+	}
+
+	if NewAtom(nil).Load() == nil {
+		t.Errorf("expected NewAtom(nil) to hold an empty map")
+	}
+}
+
+func TestAtomSwapIsAtomic(t *testing.T) {
+	t.Parallel()
+
+	// A custom hasher is shared by all versions, so this also checks that it can be used concurrently.
+	a := NewAtom(New(WithHasher(fnv.New64a)).Set("count", 0))
+
+	const goroutines, increments = 8, 200
+
+	var wg sync.WaitGroup
+
+	for g := range goroutines {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range increments {
+				a.Swap(func(m *Map) *Map {
+					count, _ := m.GetInt("count")
+
+					return m.Set("count", count+1).Set("last", g*increments+i)
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if count, _ := a.Load().GetInt("count"); count != goroutines*increments {
+		t.Errorf("count = %d, want %d", count, goroutines*increments)
+	}
+}
+
+func TestAtomSwapLargeMap(t *testing.T) {
+	t.Parallel()
+
+	// Maps with more keys than a small node are stored in a trie, whose nodes are shared by all versions.
+	initial := New()
+	for i := range smallMaxSize * 8 {
+		initial = initial.Set(fmt.Sprintf("key%d", i), 0)
+	}
+
+	a := NewAtom(initial)
+
+	const goroutines, increments = 8, 200
+
+	var wg sync.WaitGroup
+
+	for g := range goroutines {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range increments {
+				key := fmt.Sprintf("key%d", (g*increments+i)%initial.Len())
+
+				a.Swap(func(m *Map) *Map {
+					count, _ := m.GetInt(key)
+
+					updated, _ := m.Set(key, count+1).Set("new", i).Delete("new")
+
+					return updated
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	total := int64(0)
+
+	for _, k := range a.Load().Keys() {
+		count, _ := a.Load().GetInt(k)
+		total += count
+	}
+
+	if want := int64(goroutines * increments); total != want || a.Load().Len() != initial.Len() {
+		t.Errorf("total = %d with %d keys, want %d with %d keys", total, a.Load().Len(), want, initial.Len())
+	}
+
+	if v, _ := initial.GetInt("key0"); v != 0 {
+		t.Errorf("initial map was modified: key0 = %d", v)
+	}
+}
+
+func TestAtomWatchers(t *testing.T) {
+	t.Parallel()
+
+	a := NewAtom(NewFromItems("a", 1, "b", 2))
+
+	var diffs []*Map
+
+	cancel := a.Watch(func(oldValue *Map, newValue *Map, diff *Map) {
+		if oldValue == newValue {
+			t.Errorf("watcher called without a change")
+		}
+
+		diffs = append(diffs, diff)
+	})
+
+	a.Swap(func(m *Map) *Map { return m.Set("a", 10) })
+	a.Store(a.Load())
+
+	cancel()
+	a.Swap(func(m *Map) *Map { return m.Set("b", 20) })
+
+	if len(diffs) != 1 {
+		t.Fatalf("expected 1 notification, got %d", len(diffs))
+	}
+
+	if want := NewFromItems("a", 10); !diffs[0].Equals(want) {
+		t.Errorf("diff = %v, want %v", diffs[0].ToMap(), want.ToMap())
+	}
+}
+
+func TestAtomWatchersWithBooleansAndNulls(t *testing.T) {
+	t.Parallel()
+
+	a := NewAtom(mustParse(t, `{"enabled":true,"missing":null,"n":1}`))
+
+	var diffs []*Map
+
+	a.Watch(func(_ *Map, _ *Map, _ *Map) {
+		panic("watcher failed")
+	})
+	a.Watch(func(_ *Map, _ *Map, diff *Map) {
+		diffs = append(diffs, diff)
+	})
+
+	updated := a.Swap(func(m *Map) *Map { return m.Set("n", 2) })
+
+	if a.Load() != updated {
+		t.Errorf("Load() did not return the swapped version")
+	}
+
+	if len(diffs) != 1 || !diffs[0].Equals(NewFromItems("n", 2)) {
+		t.Errorf("diffs = %v, want one diff of n", diffs)
+	}
+}
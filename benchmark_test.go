@@ -91,3 +91,168 @@ func TestBench(t *testing.T) {
 
 	t.Logf("map size: %d", len(m.Keys()))
 }
+
+func BenchmarkListSet(b *testing.B) {
+	values := make([]any, 10_000)
+	for i := range values {
+		values[i] = int64(i)
+	}
+
+	m := New().Set("items", values)
+
+	b.Run("list", func(b *testing.B) {
+		for i := range b.N {
+			items, _ := m.GetList("items")
+			m = m.Set("items", items.Set(i%len(values), int64(i)))
+		}
+	})
+
+	b.Run("slice", func(b *testing.B) {
+		for i := range b.N {
+			items, _ := m.GetList("items")
+			copied := items.Values()
+			copied[i%len(values)] = int64(i)
+			m = m.Set("items", copied)
+		}
+	})
+}
+
+func BenchmarkGetKey(b *testing.B) {
+	m := New()
+	for i := range 1000 {
+		m = m.Set("key"+strconv.Itoa(i), i)
+	}
+
+	name := "key500"
+	k := NewKey(name)
+
+	b.Run("Get", func(b *testing.B) {
+		for range b.N {
+			if _, ok := m.Get(name); !ok {
+				b.Fatal("expected key to be in map")
+			}
+		}
+	})
+
+	b.Run("GetKey", func(b *testing.B) {
+		for range b.N {
+			if _, ok := m.GetKey(k); !ok {
+				b.Fatal("expected key to be in map")
+			}
+		}
+	})
+}
+
+func BenchmarkGetCompiled(b *testing.B) {
+	m := NewFromItems("request", NewFromItems("headers", NewFromItems("authorization", "token")))
+
+	path := MustParsePath("request.headers.authorization")
+	keys := []string{"request", "headers", "authorization"}
+	compiled := CompilePath(path)
+
+	b.Run("Get", func(b *testing.B) {
+		for range b.N {
+			if _, ok := m.Get(keys); !ok {
+				b.Fatal("expected path to be in map")
+			}
+		}
+	})
+
+	b.Run("GetPath", func(b *testing.B) {
+		for range b.N {
+			if _, ok := m.Get(path); !ok {
+				b.Fatal("expected path to be in map")
+			}
+		}
+	})
+
+	b.Run("GetCompiled", func(b *testing.B) {
+		for range b.N {
+			if _, ok := m.GetCompiled(compiled); !ok {
+				b.Fatal("expected path to be in map")
+			}
+		}
+	})
+}
+
+// apiPayload is a typical API response: an array of small objects with a nested object each.
+var apiPayload = func() []byte {
+	var sb strings.Builder
+
+	sb.WriteString(`{"users":[`)
+
+	for i := range 100 {
+		if i > 0 {
+			sb.WriteString(",")
+		}
+
+		fmt.Fprintf(&sb, `{"id":%d,"name":"user %d","email":"user%d@example.com","active":true,"score":%d.5,`+
+			`"address":{"street":"Main Street %d","city":"Oslo","zip":"0150","country":"NO"}}`, i, i, i, i, i)
+	}
+
+	sb.WriteString(`],"total":100,"page":1}`)
+
+	return []byte(sb.String())
+}()
+
+func BenchmarkSmallMap(b *testing.B) {
+	keys := []string{"id", "name", "email", "active", "score", "address"}
+
+	b.Run("decode", func(b *testing.B) {
+		b.ReportAllocs()
+
+		var m Map
+		for range b.N {
+			m = Map{root: nil, hasher: nil, interner: nil}
+			if err := m.UnmarshalJSON(apiPayload); err != nil {
+				b.Fatal(err)
+			}
+		}
+
+		b.ReportMetric(float64(estimatedSize(&m)), "retained-B")
+	})
+
+	b.Run("build", func(b *testing.B) {
+		b.ReportAllocs()
+
+		for range b.N {
+			m := New()
+			for i, k := range keys {
+				m = m.Set(k, i)
+			}
+		}
+	})
+
+	for _, hasherTT := range append([]struct {
+		name   string
+		hasher func() hash.Hash64
+	}{{"default", nil}}, hasherTable...) {
+		m := New(WithHasher(hasherTT.hasher))
+		for i, k := range keys {
+			m = m.Set(k, i)
+		}
+
+		b.Run("get/"+hasherTT.name, func(b *testing.B) {
+			b.ReportAllocs()
+
+			for i := range b.N {
+				if _, ok := m.Get(keys[i%len(keys)]); !ok {
+					b.Fatal("expected key to be in map")
+				}
+			}
+		})
+	}
+
+	b.Run("update", func(b *testing.B) {
+		b.ReportAllocs()
+
+		m := New()
+		for i, k := range keys {
+			m = m.Set(k, i)
+		}
+
+		for i := range b.N {
+			m = m.Set(keys[i%len(keys)], i)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	goSlices "slices"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 var (
@@ -15,6 +16,14 @@ var (
 	ErrKeyNotFound = errors.New("key not found")
 	// ErrWrongType is returned when the value of a key is not of the expected type.
 	ErrWrongType = errors.New("wrong type")
+	// ErrTypeMismatch is returned when a merge would replace a value with a value of another JSON type.
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrInvalidPath is returned when a path cannot be parsed.
+	ErrInvalidPath = errors.New("invalid path")
+	// ErrVersionNotFound is returned when a version is not in a history.
+	ErrVersionNotFound = errors.New("version not found")
+	// ErrConflict is returned when a transaction read a value that another writer changed before the commit.
+	ErrConflict = errors.New("transaction conflict")
 )
 
 type key struct {
@@ -29,30 +38,62 @@ func newKey(k string, hash uint64) key {
 // Map is an immutable hash map implementation.
 type Map struct {
 	root   *bitmasked
+	hasher *keyHasher
+	// interner deduplicates the keys and values set in the map. It is nil unless WithInterner is used.
+	interner *Interner
+}
+
+// keyHasher hashes keys with a custom hasher. A hash.Hash64 keeps state between writes, so it is guarded
+// by a mutex to let versions of a map that share it be read from several goroutines.
+type keyHasher struct {
+	mu     sync.Mutex
 	hasher hash.Hash64
 }
 
+// newKeyHasher returns the hasher for the options, or nil for the default hasher.
+func newKeyHasher(options mapOptions) *keyHasher {
+	if options.hasher == nil {
+		return nil
+	}
+
+	return &keyHasher{mu: sync.Mutex{}, hasher: options.hasher()}
+}
+
 // mapOptions.
 type mapOptions struct {
-	hasher func() hash.Hash64
+	hasher   func() hash.Hash64
+	interner *Interner
 }
 
 // defaultMapOptions are the default options used to create a map.
+// Without a hasher, keys are hashed with maphash using defaultSeed.
 var defaultMapOptions = mapOptions{
-	//hasher: fnv.New64,
-	hasher: func() hash.Hash64 { return &maphash.Hash{} },
+	hasher:   nil,
+	interner: nil,
 }
 
+// defaultSeed is shared by all maps using the default hasher, so their tries can be combined node by node.
+var defaultSeed = maphash.MakeSeed()
+
 // MapOption is a function that sets an option on a map.
 type MapOption func(*mapOptions)
 
 // WithHasher sets the hasher used to hash keys in the map.
+// By default keys are hashed with hash/maphash using a seed that is shared by all maps in the process.
 func WithHasher(h func() hash.Hash64) MapOption {
 	return func(o *mapOptions) {
 		o.hasher = h
 	}
 }
 
+// WithInterner deduplicates the keys and values set in the map, and in the maps created from it, with the interner.
+// Values decoded by UnmarshalJSON into the map are deduplicated as well.
+func WithInterner(in *Interner) MapOption {
+	return func(o *mapOptions) {
+		o.interner = in
+	}
+}
+
 // New creates a new map.
 func New(opts ...MapOption) *Map {
 	options := defaultMapOptions
@@ -61,13 +102,9 @@ func New(opts ...MapOption) *Map {
 	}
 
 	return &Map{
-		root: &bitmasked{
-			level:      0,
-			values:     newCowSlice(),
-			valueMap:   0,
-			subMapsMap: 0,
-		},
-		hasher: options.hasher(),
+		root:     newRootNode(),
+		hasher:   newKeyHasher(options),
+		interner: options.interner,
 	}
 }
 
@@ -92,17 +129,34 @@ func NewFromItems(items ...any) *Map {
 }
 
 func (m *Map) hash(key string) uint64 {
-	_, err := m.hasher.Write([]byte(key))
+	return hashKey(m.hasher, key)
+}
+
+// hashKey hashes a key with the hasher, or with maphash and defaultSeed if the hasher is nil.
+func hashKey(hasher *keyHasher, key string) uint64 {
+	if hasher == nil {
+		return maphash.String(defaultSeed, key)
+	}
+
+	hasher.mu.Lock()
+	defer hasher.mu.Unlock()
+
+	_, err := hasher.hasher.Write([]byte(key))
 	if err != nil {
 		panic(err)
 	}
 
-	sum := m.hasher.Sum64()
-	m.hasher.Reset()
+	sum := hasher.hasher.Sum64()
+	hasher.hasher.Reset()
 
 	return sum
 }
 
+// Len returns the number of keys in the map.
+func (m *Map) Len() int {
+	return m.root.size
+}
+
 // ToMap returns a native Go map with the same structure as the map.
 func (m *Map) ToMap() map[string]any {
 	return ToNativeMap(m)
@@ -112,6 +166,7 @@ func (m *Map) ToMap() map[string]any {
 func (m *Map) Copy() *Map {
 	newMap := New()
 	newMap.hasher = m.hasher
+	newMap.interner = m.interner
 
 	newRoot, ok := m.root.copy().(*bitmasked)
 	if !ok {
@@ -125,6 +180,10 @@ func (m *Map) Copy() *Map {
 
 // Equals compares two maps recursively and returns true if they are equal.
 func (m *Map) Equals(other *Map) bool {
+	if equal, known := knownEqual(m, other); known {
+		return equal
+	}
+
 	fKeys := m.Keys()
 	otherKeys := other.Keys()
 
@@ -179,11 +238,16 @@ func (m *Map) Diff(other *Map) *Map {
 }
 
 // Get retrieves the value of a key from a map.
-// The key can be a string or a list of strings.
+// The key can be a string, a list of strings or a Path.
 // If the key is a list of strings, the function will traverse the map recursively folloeing the keys in the list.
+// A Path can also traverse arrays.
 func (m *Map) Get(key any) (any, bool) {
 	switch k := key.(type) {
 	case string:
+		if m.root.isSmall() {
+			return m.root.smallGet(k)
+		}
+
 		return m.root.get(newKey(k, m.hash(k)))
 	case []string:
 		if len(k) == 0 {
@@ -208,16 +272,23 @@ func (m *Map) Get(key any) (any, bool) {
 		}
 
 		return firstValueMap.Get(restKeys)
+	case Path:
+		if len(k) == 0 {
+			return nil, false
+		}
+
+		return getPath(m, k)
 	default:
 		return nil, false
 	}
 }
 
 // GetMap retrieves the value of a key from a map and casts it to a map.
-func (m *Map) GetMap(key string) (*Map, error) {
+// The key can be anything accepted by Get.
+func (m *Map) GetMap(key any) (*Map, error) {
 	v, ok := m.Get(key)
 	if !ok {
-		return nil, fmt.Errorf("%w: '%s'", ErrKeyNotFound, key)
+		return nil, fmt.Errorf("%w: '%v'", ErrKeyNotFound, key)
 	}
 
 	valueMap, ok := v.(*Map)
@@ -228,11 +299,28 @@ func (m *Map) GetMap(key string) (*Map, error) {
 	return valueMap, nil
 }
 
+// GetList retrieves the value of a key from a map and casts it to a list.
+// The key can be anything accepted by Get.
+func (m *Map) GetList(key any) (*List, error) {
+	v, ok := m.Get(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: '%v'", ErrKeyNotFound, key)
+	}
+
+	valueList, ok := v.(*List)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected list, got %T", ErrWrongType, v)
+	}
+
+	return valueList, nil
+}
+
 // GetString retrieves the value of a key from a map and casts it to a string.
-func (m *Map) GetString(key string) (string, error) {
+// The key can be anything accepted by Get.
+func (m *Map) GetString(key any) (string, error) {
 	v, ok := m.Get(key)
 	if !ok {
-		return "", fmt.Errorf("%w: '%s'", ErrKeyNotFound, key)
+		return "", fmt.Errorf("%w: '%v'", ErrKeyNotFound, key)
 	}
 
 	switch v := v.(type) {
@@ -248,10 +336,11 @@ func (m *Map) GetString(key string) (string, error) {
 }
 
 // GetBool retrieves the value of a key from a map and casts it to a bool.
-func (m *Map) GetBool(key string) (bool, error) {
+// The key can be anything accepted by Get.
+func (m *Map) GetBool(key any) (bool, error) {
 	v, ok := m.Get(key)
 	if !ok {
-		return false, fmt.Errorf("%w: '%s'", ErrKeyNotFound, key)
+		return false, fmt.Errorf("%w: '%v'", ErrKeyNotFound, key)
 	}
 
 	valueBool, ok := v.(bool)
@@ -263,10 +352,11 @@ func (m *Map) GetBool(key string) (bool, error) {
 }
 
 // GetFloat retrieves the value of a key from a map and casts it to a float64.
-func (m *Map) GetFloat(key string) (float64, error) {
+// The key can be anything accepted by Get.
+func (m *Map) GetFloat(key any) (float64, error) {
 	v, ok := m.Get(key)
 	if !ok {
-		return 0, fmt.Errorf("%w: '%s'", ErrKeyNotFound, key)
+		return 0, fmt.Errorf("%w: '%v'", ErrKeyNotFound, key)
 	}
 
 	valueFloat, ok := v.(float64)
@@ -278,10 +368,11 @@ func (m *Map) GetFloat(key string) (float64, error) {
 }
 
 // GetInt retrieves the value of a key from a map and casts it to an int.
-func (m *Map) GetInt(key string) (int64, error) {
+// The key can be anything accepted by Get.
+func (m *Map) GetInt(key any) (int64, error) {
 	v, ok := m.Get(key)
 	if !ok {
-		return 0, fmt.Errorf("%w: '%s'", ErrKeyNotFound, key)
+		return 0, fmt.Errorf("%w: '%v'", ErrKeyNotFound, key)
 	}
 
 	switch v := v.(type) {
@@ -294,9 +385,9 @@ func (m *Map) GetInt(key string) (int64, error) {
 	}
 }
 
-// Set implements node.
+// Set returns a new map with the key set to the value. The receiver is left unchanged.
 func (m *Map) Set(key string, value any) *Map {
-	value = normalizeValue(value)
+	key, value = m.interner.internEntry(key, normalizeValue(value))
 	h := m.hash(key)
 
 	newRoot, ok := m.root.set(newKey(key, h), value).(*bitmasked)
@@ -304,9 +395,11 @@ func (m *Map) Set(key string, value any) *Map {
 		panic("expected bitmasked")
 	}
 
-	m.root = newRoot
-
-	return m
+	return &Map{
+		root:     newRoot,
+		hasher:   m.hasher,
+		interner: m.interner,
+	}
 }
 
 // Keys returns a list of all keys in the map.
@@ -320,37 +413,12 @@ func (m *Map) Keys() []string {
 // Merge merges two maps.
 // If a key exists in both maps, the value from the other map will be used.
 // If the value of a key is a map in both maps, the maps will be merged recursively.
+// Use MergeWith to configure how arrays and nil values are merged.
 func (m *Map) Merge(other *Map) *Map {
-	newMap := m
-
-	for _, k := range other.Keys() {
-		v, _ := other.Get(k)
-		if _, ok := m.Get(k); !ok {
-			newMap = newMap.Set(k, v)
+	// The default strategies never return an error.
+	merged, _, _ := m.MergeWith(other)
 
-			continue
-		}
-
-		switch t := v.(type) {
-		case *Map:
-			// Try to deep merge if both are maps. If current is  not a map, we will overwrite it.
-			currentValue, currentValueExists := newMap.Get(k)
-			currentValueTyped, currentValueIsMap := currentValue.(*Map)
-
-			if currentValueIsMap && currentValueExists {
-				t = currentValueTyped.Merge(t)
-				newMap = newMap.Set(k, t.Copy())
-
-				continue
-			}
-
-			newMap = newMap.Set(k, t)
-		default:
-			newMap = newMap.Set(k, v)
-		}
-	}
-
-	return newMap
+	return merged
 }
 
 // Delete removes a key from the map and returns a new map without the key.
@@ -359,8 +427,9 @@ func (m *Map) Delete(key string) (*Map, bool) {
 	newRoot, wasDeleted := m.root.delete(k)
 
 	return &Map{
-		root:   newRoot,
-		hasher: m.hasher,
+		root:     newRoot,
+		hasher:   m.hasher,
+		interner: m.interner,
 	}, wasDeleted
 }
 
@@ -423,21 +492,28 @@ func havePathInCommon(a *Map, b *Map) bool {
 }
 
 // Get retrieves the value of a key from a map and casts it to the desired type.
+// The key can be anything accepted by Map.Get.
 // It can return errors if the key is not found or if the value is not of the expected type.
-func Get[T any](m *Map, key string) (T, error) {
+func Get[T any](m *Map, key any) (T, error) {
 	v, ok := m.Get(key)
 	if !ok {
 		var zero T
 
-		return zero, fmt.Errorf("%w: '%s'", ErrKeyNotFound, key)
+		return zero, fmt.Errorf("%w: '%v'", ErrKeyNotFound, key)
 	}
 
 	casted, ok := v.(T)
 	if !ok {
 		var zero T
 
-		return zero, fmt.Errorf("%w: '%s'", ErrWrongType, key)
+		return zero, fmt.Errorf("%w: '%v'", ErrWrongType, key)
 	}
 
 	return casted, nil
 }
+
+// sameHasher returns true if two maps hash keys the same way, so their tries can be combined node by node.
+// Only the default hasher is known to be shared, custom hashers are compared by identity.
+func sameHasher(a *keyHasher, b *keyHasher) bool {
+	return a == b
+}
@@ -1,6 +1,7 @@
 package jsonchamp
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 )
@@ -306,6 +307,27 @@ func TestDiffMapNoNested(t *testing.T) {
 			diff:          NewFromItems("b", nil),
 			expectedError: nil,
 		},
+		{
+			name:          "unchanged booleans and nulls",
+			first:         NewFromItems("f", true, "n", nil, "a", 1),
+			second:        NewFromItems("f", true, "n", nil, "a", 2),
+			diff:          NewFromItems("a", 2),
+			expectedError: nil,
+		},
+		{
+			name:          "changed boolean",
+			first:         NewFromItems("f", true, "n", nil),
+			second:        NewFromItems("f", false, "n", nil),
+			diff:          NewFromItems("f", false),
+			expectedError: nil,
+		},
+		{
+			name:          "null replaced by a value",
+			first:         NewFromItems("n", nil),
+			second:        NewFromItems("n", "x"),
+			diff:          NewFromItems("n", "x"),
+			expectedError: nil,
+		},
 	}
 
 	for _, tt := range tests {
@@ -640,3 +662,58 @@ func TestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestMapSetIsPersistent(t *testing.T) {
+	t.Parallel()
+
+	original := New()
+	for i := range 200 {
+		original = original.Set(string(rune('a'+i%26))+string(rune('a'+i/26)), i)
+	}
+
+	updated := original
+	for _, k := range original.Keys() {
+		updated = updated.Set(k, "changed")
+	}
+
+	for _, k := range original.Keys() {
+		v, _ := original.Get(k)
+		if v == "changed" {
+			t.Fatalf("original map was modified at key %s", k)
+		}
+	}
+}
+
+func TestDeleteMissingKeyKeepsOtherKeys(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	for i := range 50 {
+		m = m.Set(fmt.Sprintf("present_%d", i), i)
+	}
+
+	for i := range 500 {
+		m, _ = m.Delete(fmt.Sprintf("missing_%d", i))
+	}
+
+	if got := len(m.Keys()); got != 50 {
+		t.Fatalf("expected 50 keys after deleting missing keys, got %d", got)
+	}
+}
+
+func TestMapLen(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	for i := range 1000 {
+		m = m.Set(fmt.Sprintf("key_%d", i), i)
+	}
+
+	m = m.Set("key_1", "replaced")
+	m, _ = m.Delete("key_2")
+	m, _ = m.Delete("missing")
+
+	if m.Len() != 999 || len(m.Keys()) != 999 {
+		t.Fatalf("expected 999 keys, got Len() = %d and %d keys", m.Len(), len(m.Keys()))
+	}
+}
@@ -0,0 +1,206 @@
+package jsonchamp
+
+import (
+	goSlices "slices"
+)
+
+// ChangeKind is the kind of a change between two documents.
+type ChangeKind int
+
+const (
+	// ChangeAdded means the path only exists in the new document.
+	ChangeAdded ChangeKind = iota
+	// ChangeRemoved means the path only exists in the old document.
+	ChangeRemoved
+	// ChangeModified means the value at the path changed but kept its JSON type.
+	ChangeModified
+	// ChangeTypeChanged means the value at the path changed to another JSON type.
+	ChangeTypeChanged
+)
+
+// String returns the name of the change kind.
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	case ChangeTypeChanged:
+		return "type-changed"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is a single difference between two documents.
+type Change struct {
+	// Path is the location of the value. Array indices refer to the old array for removed elements
+	// and to the new array otherwise.
+	Path Path
+	Kind ChangeKind
+	// Old is the value in the old document. It is nil for added values.
+	Old any
+	// New is the value in the new document. It is nil for removed values.
+	New any
+}
+
+type diffOptions struct {
+	identityKeys IdentityKeys
+}
+
+// DiffOption is a function that sets an option on a structured diff.
+type DiffOption func(*diffOptions)
+
+// WithDiffIdentityKeys compares the records of the arrays matched by the identity keys by their identity
+// instead of by their position.
+func WithDiffIdentityKeys(keys IdentityKeys) DiffOption {
+	return func(o *diffOptions) {
+		o.identityKeys = append(o.identityKeys, keys...)
+	}
+}
+
+// Changes returns the list of changes that turn a into b, ordered by path.
+// Nested maps are compared key by key and arrays are aligned with DiffLists, so only the leaves that
+// changed are reported.
+// Unlike Diff, a removed key and a key set to nil are reported as different kinds of changes.
+func Changes(a *Map, b *Map, opts ...DiffOption) []Change {
+	var options diffOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return changesMap(nil, nil, a, b, options)
+}
+
+func changesMap(changes []Change, path Path, a *Map, b *Map, options diffOptions) []Change {
+	if equal, known := knownEqual(a, b); known && equal {
+		return changes
+	}
+
+	keys := union(a.Keys(), b.Keys())
+	goSlices.Sort(keys)
+
+	for _, k := range keys {
+		aValue, aExists := a.Get(k)
+		bValue, bExists := b.Get(k)
+
+		keyPath := path.Child(k)
+
+		switch {
+		case !bExists:
+			changes = append(changes, Change{Path: keyPath, Kind: ChangeRemoved, Old: aValue, New: nil})
+		case !aExists:
+			changes = append(changes, Change{Path: keyPath, Kind: ChangeAdded, Old: nil, New: bValue})
+		default:
+			changes = changesValue(changes, keyPath, aValue, bValue, options)
+		}
+	}
+
+	return changes
+}
+
+func changesList(changes []Change, path Path, a *List, b *List, options diffOptions) []Change {
+	if field, ok := options.identityKeys.fieldFor(path); ok {
+		if recordChanges, ok := changesRecords(changes, path, a, b, field, options); ok {
+			return recordChanges
+		}
+	}
+
+	for _, edit := range DiffLists(a, b) {
+		switch edit.Kind {
+		case EditDelete:
+			indexPath := path.Index(edit.OldIndex)
+			changes = append(changes, Change{Path: indexPath, Kind: ChangeRemoved, Old: edit.Old, New: nil})
+		case EditInsert:
+			indexPath := path.Index(edit.NewIndex)
+			changes = append(changes, Change{Path: indexPath, Kind: ChangeAdded, Old: nil, New: edit.New})
+		case EditModify:
+			indexPath := path.Index(edit.NewIndex)
+			changes = changesValue(changes, indexPath, edit.Old, edit.New, options)
+		}
+	}
+
+	return changes
+}
+
+func changesValue(changes []Change, path Path, a any, b any, options diffOptions) []Change {
+	if jsonKind(a) != jsonKind(b) {
+		return append(changes, Change{Path: path, Kind: ChangeTypeChanged, Old: a, New: b})
+	}
+
+	switch aTyped := a.(type) {
+	case *Map:
+		bMap, _ := b.(*Map)
+
+		return changesMap(changes, path, aTyped, bMap, options)
+	case *List:
+		bList, _ := b.(*List)
+
+		return changesList(changes, path, aTyped, bList, options)
+	}
+
+	if !equalsAny(a, b) {
+		return append(changes, Change{Path: path, Kind: ChangeModified, Old: a, New: b})
+	}
+
+	return changes
+}
+
+// changesRecords compares two arrays of records by their identity field.
+// Removed records are reported with their index in a, and added and changed records with their index in b.
+// It returns false if any element is not a record with the identity field.
+func changesRecords(changes []Change, path Path, a *List, b *List, field string, options diffOptions) ([]Change, bool) {
+	oldIndexes := make(map[string]int, a.Len())
+
+	for i, record := range a.All() {
+		id, ok := recordIdentity(record, field)
+		if !ok {
+			return changes, false
+		}
+
+		if _, exists := oldIndexes[id]; !exists {
+			oldIndexes[id] = i
+		}
+	}
+
+	newIDs := make([]string, b.Len())
+	newIndexes := make(map[string]struct{}, b.Len())
+
+	for j, record := range b.All() {
+		id, ok := recordIdentity(record, field)
+		if !ok {
+			return changes, false
+		}
+
+		newIDs[j] = id
+		newIndexes[id] = struct{}{}
+	}
+
+	for i, record := range a.All() {
+		id, _ := recordIdentity(record, field)
+		if _, exists := newIndexes[id]; !exists || oldIndexes[id] != i {
+			indexPath := path.Index(i)
+			changes = append(changes, Change{Path: indexPath, Kind: ChangeRemoved, Old: record, New: nil})
+		}
+	}
+
+	matched := make(map[string]struct{}, b.Len())
+
+	for j, record := range b.All() {
+		indexPath := path.Index(j)
+
+		i, exists := oldIndexes[newIDs[j]]
+		if _, seen := matched[newIDs[j]]; !exists || seen {
+			changes = append(changes, Change{Path: indexPath, Kind: ChangeAdded, Old: nil, New: record})
+
+			continue
+		}
+
+		matched[newIDs[j]] = struct{}{}
+		changes = changesValue(changes, indexPath, a.at(i), record, options)
+	}
+
+	return changes, true
+}
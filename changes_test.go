@@ -0,0 +1,83 @@
+package jsonchamp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want []Change
+	}{
+		{
+			name: "equal documents",
+			a:    `{"a":{"b":[1,2]}}`,
+			b:    `{"a":{"b":[1,2]}}`,
+			want: nil,
+		},
+		{
+			name: "added, removed and modified keys",
+			a:    `{"a":1,"b":"x"}`,
+			b:    `{"a":2,"c":true}`,
+			want: []Change{
+				{Path: MustParsePath("a"), Kind: ChangeModified, Old: int64(1), New: int64(2)},
+				{Path: MustParsePath("b"), Kind: ChangeRemoved, Old: "x", New: nil},
+				{Path: MustParsePath("c"), Kind: ChangeAdded, Old: nil, New: true},
+			},
+		},
+		{
+			name: "removed key and null value are different",
+			a:    `{"a":1,"b":2}`,
+			b:    `{"a":null}`,
+			want: []Change{
+				{Path: MustParsePath("a"), Kind: ChangeTypeChanged, Old: int64(1), New: nil},
+				{Path: MustParsePath("b"), Kind: ChangeRemoved, Old: int64(2), New: nil},
+			},
+		},
+		{
+			name: "nested maps",
+			a:    `{"a":{"b":{"c":"x"}}}`,
+			b:    `{"a":{"b":{"c":"y"}}}`,
+			want: []Change{
+				{Path: MustParsePath("a.b.c"), Kind: ChangeModified, Old: "x", New: "y"},
+			},
+		},
+		{
+			name: "arrays",
+			a:    `{"a":[1,{"b":1},3]}`,
+			b:    `{"a":[1,{"b":2}]}`,
+			want: []Change{
+				{Path: MustParsePath("a.1.b"), Kind: ChangeModified, Old: int64(1), New: int64(2)},
+				{Path: MustParsePath("a.2"), Kind: ChangeRemoved, Old: int64(3), New: nil},
+			},
+		},
+		{
+			name: "integer to float is a modification",
+			a:    `{"a":1}`,
+			b:    `{"a":1.5}`,
+			want: []Change{
+				{Path: MustParsePath("a"), Kind: ChangeModified, Old: int64(1), New: 1.5},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := Changes(mustParse(t, tt.a), mustParse(t, tt.b))
+			if !slices.EqualFunc(got, tt.want, equalChange) {
+				t.Errorf("Changes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func equalChange(a Change, b Change) bool {
+	return slices.Equal(a.Path, b.Path) && a.Kind == b.Kind && equalsAny(a.Old, b.Old) && equalsAny(a.New, b.New)
+}
@@ -0,0 +1,122 @@
+package jsonchamp
+
+import (
+	goSlices "slices"
+)
+
+// Key is a map key with its hash computed ahead of time.
+// The hash is only valid for maps using the same hasher as the key, and other maps hash the key again.
+type Key struct {
+	key    key
+	hasher *keyHasher
+}
+
+// NewKey creates a key for maps using the default hasher, which includes all nested maps.
+func NewKey(name string) Key {
+	return Key{key: newKey(name, hashKey(nil, name)), hasher: nil}
+}
+
+// Key creates a key for the hasher of the map.
+func (m *Map) Key(name string) Key {
+	return Key{key: newKey(name, m.hash(name)), hasher: m.hasher}
+}
+
+// String returns the name of the key.
+func (k Key) String() string {
+	return k.key.key
+}
+
+// keyFor returns the hashed key for the map, hashing it again if the map uses another hasher.
+func (m *Map) keyFor(k Key) key {
+	if sameHasher(m.hasher, k.hasher) {
+		return k.key
+	}
+
+	return newKey(k.key.key, m.hash(k.key.key))
+}
+
+// GetKey retrieves the value of a precomputed key.
+func (m *Map) GetKey(k Key) (any, bool) {
+	return m.root.get(m.keyFor(k))
+}
+
+// SetKey returns a new map with the precomputed key set to the value.
+func (m *Map) SetKey(k Key, value any) *Map {
+	hashed := m.keyFor(k)
+	hashed.key, value = m.interner.internEntry(hashed.key, normalizeValue(value))
+
+	newRoot, ok := m.root.set(hashed, value).(*bitmasked)
+	if !ok {
+		panic("expected bitmasked")
+	}
+
+	return &Map{root: newRoot, hasher: m.hasher, interner: m.interner}
+}
+
+// compiledSegment is a segment of a CompiledPath. Keys of digits also carry their index,
+// so they can address array elements like Path does.
+type compiledSegment struct {
+	key     Key
+	index   int
+	isIndex bool
+}
+
+// CompiledPath is a Path with the hashes of its keys computed ahead of time, for paths that are read often.
+// The hashes are computed with the default hasher.
+type CompiledPath struct {
+	path     Path
+	segments []compiledSegment
+}
+
+// CompilePath computes the hashes of the keys in a path.
+func CompilePath(path Path) CompiledPath {
+	segments := make([]compiledSegment, len(path))
+
+	for i, segment := range path {
+		index, isIndex := segment.Index, segment.IsIndex
+		if !isIndex {
+			index, isIndex = parseIndex(segment.Key)
+		}
+
+		segments[i] = compiledSegment{key: NewKey(segment.String()), index: index, isIndex: isIndex}
+	}
+
+	return CompiledPath{path: goSlices.Clone(path), segments: segments}
+}
+
+// Path returns the path that was compiled.
+func (p CompiledPath) Path() Path {
+	return goSlices.Clone(p.path)
+}
+
+// GetCompiled retrieves the value at a compiled path. It behaves like Get with the path that was compiled.
+func (m *Map) GetCompiled(p CompiledPath) (any, bool) {
+	if len(p.segments) == 0 {
+		return nil, false
+	}
+
+	var current any = m
+
+	for _, segment := range p.segments {
+		var ok bool
+
+		switch t := current.(type) {
+		case *Map:
+			current, ok = t.GetKey(segment.key)
+		case *List:
+			if !segment.isIndex {
+				return nil, false
+			}
+
+			current, ok = t.Get(segment.index)
+		default:
+			return nil, false
+		}
+
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
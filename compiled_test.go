@@ -0,0 +1,82 @@
+package jsonchamp
+
+import (
+	"hash/fnv"
+	"testing"
+)
+
+func TestGetAndSetKey(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":1,"b":"x"}`)
+	custom := New(WithHasher(fnv.New64a)).Set("a", 1)
+
+	tests := []struct {
+		name string
+		m    *Map
+		key  Key
+	}{
+		{name: "default hasher", m: m, key: NewKey("a")},
+		{name: "key of the map", m: custom, key: custom.Key("a")},
+		{name: "key of another hasher", m: custom, key: NewKey("a")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if v, ok := tt.m.GetKey(tt.key); !ok || v != int64(1) {
+				t.Errorf("GetKey(%s) = %v, %v, want 1, true", tt.key, v, ok)
+			}
+
+			updated := tt.m.SetKey(tt.key, 2)
+			if v, _ := updated.Get("a"); v != int64(2) {
+				t.Errorf("SetKey(%s) stored %v, want 2", tt.key, v)
+			}
+
+			if updated.Len() != tt.m.Len() {
+				t.Errorf("SetKey() changed the length from %d to %d", tt.m.Len(), updated.Len())
+			}
+		})
+	}
+
+	if _, ok := m.GetKey(NewKey("missing")); ok {
+		t.Errorf("expected missing key not to be found")
+	}
+}
+
+func TestGetCompiled(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":{"b":[{"c":1}],"0":"zero"}}`)
+
+	tests := []struct {
+		path string
+		want any
+		ok   bool
+	}{
+		{path: "a.b.0.c", want: int64(1), ok: true},
+		{path: "a[0]", want: "zero", ok: true},
+		{path: `a.\0`, want: "zero", ok: true},
+		{path: "a.b.1", want: nil, ok: false},
+		{path: "a.b.x", want: nil, ok: false},
+	}
+
+	for _, tt := range tests {
+		path := MustParsePath(tt.path)
+		compiled := CompilePath(path)
+
+		got, ok := m.GetCompiled(compiled)
+		if ok != tt.ok || !equalsAny(got, tt.want) {
+			t.Errorf("GetCompiled(%s) = %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+
+		if wantGot, wantOk := m.Get(path); ok != wantOk || !equalsAny(got, wantGot) {
+			t.Errorf("GetCompiled(%s) = %v, %v, but Get returns %v, %v", tt.path, got, ok, wantGot, wantOk)
+		}
+
+		if !compiled.Path().Equal(path) {
+			t.Errorf("Path() = %v, want %v", compiled.Path(), path)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+package jsonchamp
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha3"
+	"encoding/binary"
+	"fmt"
+	"math"
+	goSlices "slices"
+)
+
+// Tags of the values in the canonical form hashed by ContentHash.
+// Native maps and slices have their own tags, because Equals never considers them equal to a Map or List.
+const (
+	digestNull         = 'n'
+	digestTrue         = 't'
+	digestFalse        = 'f'
+	digestString       = 's'
+	digestInt          = 'i'
+	digestUint         = 'u'
+	digestFloat        = 'd'
+	digestObject       = 'o'
+	digestArray        = 'a'
+	digestNativeObject = 'O'
+	digestNativeArray  = 'A'
+	digestStrings      = 'S'
+	digestEntry        = 'e'
+	digestListLeaf     = 'l'
+	digestListBranch   = 'b'
+	digestLargeObject  = 'h'
+)
+
+// digest is the content digest of a value.
+type digest struct {
+	sum [sha256.Size]byte
+	// inexact is true if the value contains a float. Equals compares floats within a tolerance,
+	// so values with different digests may still be equal.
+	inexact bool
+}
+
+// ContentHash returns a SHA-256 digest of the canonical form of the map. Maps with equal content have the
+// same digest, regardless of their hasher, the process and the order the keys were set in, so it can be used
+// as an ETag or a cache key without marshalling the map.
+//
+// Small maps are hashed from the sorted digests of their entries. Larger maps are hashed from an LtHash of their
+// entries, a homomorphic hash that is collision resistant and does not depend on the order of the entries,
+// so it is cached in the trie nodes and combined from the nodes of any trie shape. Arrays are hashed from
+// the digests of the nodes of their trie, whose shape only depends on the number of elements.
+// All these digests are cached, so after an update only the trie nodes on the path to the changed value
+// are hashed again.
+//
+// Once the digests of two maps are cached, Equals, Diff and Changes use them to skip maps that are equal.
+func (m *Map) ContentHash() [sha256.Size]byte {
+	return m.contentDigest().sum
+}
+
+// contentDigest returns the digest of the map, which is cached in its root node.
+func (m *Map) contentDigest() digest {
+	if cached := m.root.digest.Load(); cached != nil {
+		return *cached
+	}
+
+	var d digest
+
+	if m.Len() < ltHashMinSize {
+		entries := make([]digest, 0, m.Len())
+
+		m.root.all(func(v *value) bool {
+			entries = append(entries, entryDigest(v))
+
+			return true
+		})
+
+		d = sortedDigest(digestObject, entries)
+	} else {
+		var h ltHash
+		m.root.addLtHash(&h)
+
+		d = digest{sum: sha256.Sum256(h.appendTo([]byte{digestLargeObject})), inexact: h.inexact}
+	}
+
+	m.root.digest.Store(&d)
+
+	return d
+}
+
+// cachedDigest returns the digest of the map if it is cached.
+func (m *Map) cachedDigest() (digest, bool) {
+	if cached := m.root.digest.Load(); cached != nil {
+		return *cached, true
+	}
+
+	var zero digest
+
+	return zero, false
+}
+
+// knownEqual compares two maps without visiting their entries. It returns false as the second value
+// if the maps have to be compared entry by entry.
+func knownEqual(a *Map, b *Map) (bool, bool) {
+	if a.root == b.root {
+		return true, true
+	}
+
+	aDigest, aOk := a.cachedDigest()
+	bDigest, bOk := b.cachedDigest()
+
+	switch {
+	case !aOk || !bOk:
+		return false, false
+	case aDigest.sum == bDigest.sum:
+		return true, true
+	case !aDigest.inexact && !bDigest.inexact:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// sortedDigest hashes the digests in sorted order, so the result does not depend on the order of the entries.
+func sortedDigest(tag byte, entries []digest) digest {
+	goSlices.SortFunc(entries, func(a digest, b digest) int {
+		return bytes.Compare(a.sum[:], b.sum[:])
+	})
+
+	buf := make([]byte, 0, 1+len(entries)*sha256.Size)
+	buf = append(buf, tag)
+
+	inexact := false
+
+	for _, entry := range entries {
+		buf = append(buf, entry.sum[:]...)
+		inexact = inexact || entry.inexact
+	}
+
+	return digest{sum: sha256.Sum256(buf), inexact: inexact}
+}
+
+// ltHash is an LtHash16 as described in "Securing Update Propagation with Homomorphic Hashing"
+// by Lewi, Kim, Maykov and Weis: a vector of 16-bit components, to which entries are added by adding
+// their expansion componentwise modulo 2^16. The components are stored four to a word.
+type ltHash struct {
+	words [ltHashComponents / 4]uint64
+	// inexact is true if an entry contains a float.
+	inexact bool
+}
+
+const (
+	ltHashComponents = 1024
+	// ltHashMinSize is the smallest number of keys in a trie node that caches its LtHash.
+	// Smaller sub trees are added entry by entry, which saves the memory of caching their hashes.
+	ltHashMinSize = 16
+)
+
+// add adds another hash componentwise. The top bits of the components are added separately,
+// so carries do not cross into the next component.
+func (h *ltHash) add(other *ltHash) {
+	const topBits = 0x8000800080008000
+
+	for i, word := range other.words {
+		h.words[i] = ((h.words[i] &^ topBits) + (word &^ topBits)) ^ ((h.words[i] ^ word) & topBits)
+	}
+
+	h.inexact = h.inexact || other.inexact
+}
+
+// addEntry adds the expansion of an entry digest with SHAKE128.
+func (h *ltHash) addEntry(entry digest) {
+	var expanded [ltHashComponents * 2]byte
+
+	shake := sha3.NewSHAKE128()
+	_, _ = shake.Write(entry.sum[:])
+	_, _ = shake.Read(expanded[:])
+
+	var other ltHash
+	for i := range other.words {
+		other.words[i] = binary.LittleEndian.Uint64(expanded[i*8:])
+	}
+
+	other.inexact = entry.inexact
+
+	h.add(&other)
+}
+
+func (h *ltHash) appendTo(buf []byte) []byte {
+	for _, word := range h.words {
+		buf = binary.LittleEndian.AppendUint64(buf, word)
+	}
+
+	return buf
+}
+
+// addLtHash adds the entries of the node and its sub nodes to h, caching the hash of nodes with at least
+// ltHashMinSize keys.
+func (b *bitmasked) addLtHash(h *ltHash) {
+	if cached := b.lt.Load(); cached != nil {
+		h.add(cached)
+
+		return
+	}
+
+	if b.size < ltHashMinSize {
+		b.all(func(v *value) bool {
+			h.addEntry(entryDigest(v))
+
+			return true
+		})
+
+		return
+	}
+
+	var own ltHash
+
+	for _, n := range b.values.Values() {
+		switch t := n.(type) {
+		case *value:
+			own.addEntry(entryDigest(t))
+		case *collision:
+			for _, v := range t.values {
+				own.addEntry(entryDigest(v))
+			}
+		case *bitmasked:
+			t.addLtHash(&own)
+		}
+	}
+
+	b.lt.Store(&own)
+	h.add(&own)
+}
+
+// contentDigest returns the digest of the list, computed from the cached digests of its trie nodes.
+// The shape of the trie only depends on the number of elements, so equal lists have the same digest.
+func (l *List) contentDigest() digest {
+	if cached := l.digest.Load(); cached != nil {
+		return *cached
+	}
+
+	root := l.root.contentDigest()
+
+	buf := binary.BigEndian.AppendUint64([]byte{digestArray}, uint64(l.count))
+	buf = append(buf, root.sum[:]...)
+	inexact := root.inexact
+
+	for _, v := range l.tail {
+		d := digestOf(v)
+		buf = append(buf, d.sum[:]...)
+		inexact = inexact || d.inexact
+	}
+
+	d := digest{sum: sha256.Sum256(buf), inexact: inexact}
+	l.digest.Store(&d)
+
+	return d
+}
+
+func (n *listNode) contentDigest() digest {
+	if cached := n.digest.Load(); cached != nil {
+		return *cached
+	}
+
+	var d digest
+
+	if n.values != nil {
+		d = sliceDigest(digestListLeaf, n.values)
+	} else {
+		buf := make([]byte, 0, 1+len(n.children)*sha256.Size)
+		buf = append(buf, digestListBranch)
+
+		for _, child := range n.children {
+			childDigest := child.contentDigest()
+			buf = append(buf, childDigest.sum[:]...)
+			d.inexact = d.inexact || childDigest.inexact
+		}
+
+		d.sum = sha256.Sum256(buf)
+	}
+
+	n.digest.Store(&d)
+
+	return d
+}
+
+func entryDigest(v *value) digest {
+	return keyValueDigest(v.key.key, v.value)
+}
+
+func keyValueDigest(k string, v any) digest {
+	valueDigest := digestOf(v)
+
+	buf := appendString([]byte{digestEntry}, k)
+
+	return digest{sum: sha256.Sum256(append(buf, valueDigest.sum[:]...)), inexact: valueDigest.inexact}
+}
+
+func sliceDigest(tag byte, values []any) digest {
+	buf := make([]byte, 0, 1+len(values)*sha256.Size)
+	buf = append(buf, tag)
+
+	inexact := false
+
+	for _, v := range values {
+		d := digestOf(v)
+		buf = append(buf, d.sum[:]...)
+		inexact = inexact || d.inexact
+	}
+
+	return digest{sum: sha256.Sum256(buf), inexact: inexact}
+}
+
+func nativeMapDigest(m map[string]any) digest {
+	entries := make([]digest, 0, len(m))
+	for k, v := range m {
+		entries = append(entries, keyValueDigest(k, v))
+	}
+
+	return sortedDigest(digestNativeObject, entries)
+}
+
+// appendString appends the length of the string before it, so that consecutive strings cannot be confused.
+func appendString(buf []byte, s string) []byte {
+	return append(binary.BigEndian.AppendUint64(buf, uint64(len(s))), s...)
+}
+
+// digestOf returns the digest of a value. Values are converted like in equalsAny, so values that are equal
+// have the same digest unless they contain floats.
+func digestOf(v any) digest {
+	var buf []byte
+
+	inexact := false
+
+	switch t := toLargestType(v).(type) {
+	case *Map:
+		return t.contentDigest()
+	case *List:
+		return t.contentDigest()
+	case []any:
+		return sliceDigest(digestNativeArray, t)
+	case map[string]any:
+		return nativeMapDigest(t)
+	case nil:
+		buf = []byte{digestNull}
+	case bool:
+		buf = []byte{digestFalse}
+		if t {
+			buf = []byte{digestTrue}
+		}
+	case string:
+		buf = appendString([]byte{digestString}, t)
+	case int64:
+		buf = binary.BigEndian.AppendUint64([]byte{digestInt}, uint64(t))
+	case uint64:
+		buf = binary.BigEndian.AppendUint64([]byte{digestUint}, t)
+	case float64:
+		buf = binary.BigEndian.AppendUint64([]byte{digestFloat}, math.Float64bits(t))
+		inexact = true
+	case []string:
+		buf = []byte{digestStrings}
+		for _, s := range t {
+			buf = appendString(buf, s)
+		}
+	default:
+		panic(fmt.Sprintf("type %T not supported", v))
+	}
+
+	return digest{sum: sha256.Sum256(buf), inexact: inexact}
+}
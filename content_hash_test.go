@@ -0,0 +1,188 @@
+package jsonchamp
+
+import (
+	"fmt"
+	"hash/fnv"
+	"testing"
+)
+
+func TestContentHash(t *testing.T) {
+	t.Parallel()
+
+	base := mustParse(t, `{"a":1,"b":{"c":[1,"x",{"d":true}]},"e":null,"f":1.5}`)
+	withoutE, _ := base.Delete("e")
+	withoutA, _ := base.Delete("a")
+
+	tests := []struct {
+		name  string
+		m     *Map
+		equal bool
+	}{
+		{name: "same content", m: mustParse(t, `{"f":1.5,"e":null,"b":{"c":[1,"x",{"d":true}]},"a":1}`), equal: true},
+		{
+			name: "different hasher",
+			m: New(WithHasher(fnv.New64a)).
+				Set("b", mustParse(t, `{"c":[1,"x",{"d":true}]}`)).
+				Set("a", 1).Set("e", nil).Set("f", 1.5),
+			equal: true,
+		},
+		{name: "changed nested value", m: base.Set("b", mustParse(t, `{"c":[1,"x",{"d":false}]}`)), equal: false},
+		{name: "reordered array", m: base.Set("b", mustParse(t, `{"c":["x",1,{"d":true}]}`)), equal: false},
+		{name: "int instead of float", m: base.Set("f", 1), equal: false},
+		{name: "string instead of int", m: base.Set("a", "1"), equal: false},
+		{name: "removed key", m: withoutE, equal: false},
+		{name: "moved value", m: withoutA.Set("g", 1), equal: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := base.ContentHash() == tt.m.ContentHash(); got != tt.equal {
+				t.Errorf("ContentHash() equal = %v, want %v", got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestContentHashAfterUpdates(t *testing.T) {
+	t.Parallel()
+
+	m := New()
+	for i := range 1000 {
+		m = m.Set(fmt.Sprintf("key%d", i), i)
+	}
+
+	before := m.ContentHash()
+
+	updated := m.Set("key500", -1)
+	if updated.ContentHash() == before {
+		t.Errorf("ContentHash() did not change after Set")
+	}
+
+	if m.ContentHash() != before {
+		t.Errorf("ContentHash() of the original map changed after Set")
+	}
+
+	restored := updated.Set("key500", 500)
+	if restored.ContentHash() != before {
+		t.Errorf("ContentHash() after restoring the value = %x, want %x", restored.ContentHash(), before)
+	}
+
+	deleted, _ := m.Delete("key10")
+	if deleted.Set("key10", 10).ContentHash() != before {
+		t.Errorf("ContentHash() after deleting and setting the key again differs")
+	}
+}
+
+func TestContentHashOfLargeMaps(t *testing.T) {
+	t.Parallel()
+
+	// Large maps are hashed from the trie nodes, whose shape depends on the hasher and the order of updates.
+	forward := New()
+	backward := New(WithHasher(fnv.New64a))
+
+	for i := range 1000 {
+		forward = forward.Set(fmt.Sprintf("key%d", i), i)
+		backward = backward.Set(fmt.Sprintf("key%d", 999-i), 999-i)
+	}
+
+	if forward.ContentHash() != backward.ContentHash() {
+		t.Errorf("large maps with the same content have different digests")
+	}
+
+	// Below ltHashMinSize keys maps are hashed from their sorted entries instead.
+	small, _ := forward.Delete("key0")
+	for i := 1; i < 1000-ltHashMinSize+1; i++ {
+		small, _ = small.Delete(fmt.Sprintf("key%d", i))
+	}
+
+	if small.Len() != ltHashMinSize-1 {
+		t.Fatalf("Len() = %d, want %d", small.Len(), ltHashMinSize-1)
+	}
+
+	large := small.Set("key0", 0)
+	if large.ContentHash() == small.ContentHash() {
+		t.Errorf("digest did not change after Set")
+	}
+
+	restored, _ := large.Delete("key0")
+	if restored.ContentHash() != small.ContentHash() {
+		t.Errorf("ContentHash() after deleting the added key = %x, want %x", restored.ContentHash(), small.ContentHash())
+	}
+}
+
+func TestEqualsAndDiffWithContentHash(t *testing.T) {
+	t.Parallel()
+
+	a := mustParse(t, `{"a":{"b":1},"c":[1,2]}`)
+	b := mustParse(t, `{"c":[1,2],"a":{"b":1}}`)
+	c := mustParse(t, `{"a":{"b":2},"c":[1,2]}`)
+
+	for _, m := range []*Map{a, b, c} {
+		m.ContentHash()
+	}
+
+	if !a.Equals(b) {
+		t.Errorf("Equals() = false, want true")
+	}
+
+	if a.Equals(c) {
+		t.Errorf("Equals() = true, want false")
+	}
+
+	if diff := a.Diff(b); diff.Len() != 0 {
+		t.Errorf("Diff() = %v, want empty", diff)
+	}
+
+	if diff := a.Diff(c); diff.Len() != 1 {
+		t.Errorf("Diff() = %v, want one key", diff)
+	}
+
+	// Floats are compared within a tolerance, so different digests do not make maps with floats unequal.
+	tenth := 0.1
+	x := New().Set("f", tenth+0.2)
+	y := New().Set("f", 0.3)
+
+	if x.ContentHash() == y.ContentHash() {
+		t.Fatalf("expected different digests")
+	}
+
+	if !x.Equals(y) {
+		t.Errorf("Equals() = false, want true")
+	}
+}
+
+func TestContentHashOfLists(t *testing.T) {
+	t.Parallel()
+
+	values := make([]any, 5000)
+	for i := range values {
+		values[i] = int64(i)
+	}
+
+	built := NewList(values...)
+	appended := NewList().Append(values...)
+
+	if built.contentDigest() != appended.contentDigest() {
+		t.Errorf("lists with the same elements built differently have different digests")
+	}
+
+	updated := built.Set(100, "x")
+	if updated.contentDigest() == built.contentDigest() {
+		t.Errorf("digest did not change after Set")
+	}
+
+	// Only the nodes on the path to the changed element are new, and the others keep their cached digests.
+	if updated.root.children[1] != built.root.children[1] || updated.root.children[1].digest.Load() == nil {
+		t.Errorf("expected unchanged nodes to be shared with their cached digests")
+	}
+
+	if restored := updated.Set(100, int64(100)); restored.contentDigest() != built.contentDigest() {
+		t.Errorf("digest after restoring the element differs")
+	}
+
+	if NewList(1, 2).contentDigest() == NewList(2, 1).contentDigest() {
+		t.Errorf("reordered lists have the same digest")
+	}
+}
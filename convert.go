@@ -138,14 +138,23 @@ func ToStruct(m *Map, out any) error {
 
 			structValue.Field(i).SetBool(booled)
 		case reflect.Slice:
-			slice := reflect.ValueOf(mapVal)
-			if slice.Len() == 0 {
+			list, ok := mapVal.(*List)
+			if !ok {
+				return fmt.Errorf("expected field %s to be a list, got %T", champName, mapVal)
+			}
+
+			if list.Len() == 0 {
 				structValue.Field(i).Set(reflect.Zero(fieldType.Type))
 
 				continue
 			}
 
-			structValue.Field(i).Set(reflect.ValueOf(mapVal))
+			slice, err := listToSlice(list, fieldType.Type)
+			if err != nil {
+				return fmt.Errorf("failed to convert field %s: %w", champName, err)
+			}
+
+			structValue.Field(i).Set(slice)
 		case reflect.Map:
 			return errors.New("map fields are not supported. use a struct instead")
 		case reflect.Ptr:
@@ -158,6 +167,29 @@ func ToStruct(m *Map, out any) error {
 	return nil
 }
 
+// listToSlice converts a List to a slice of the given type, converting the elements where needed.
+func listToSlice(list *List, sliceType reflect.Type) (reflect.Value, error) {
+	elemType := sliceType.Elem()
+	slice := reflect.MakeSlice(sliceType, 0, list.Len())
+
+	for i, item := range list.All() {
+		itemValue := reflect.ValueOf(item)
+
+		switch {
+		case !itemValue.IsValid():
+			slice = reflect.Append(slice, reflect.Zero(elemType))
+		case itemValue.Type().AssignableTo(elemType):
+			slice = reflect.Append(slice, itemValue)
+		case itemValue.Type().ConvertibleTo(elemType):
+			slice = reflect.Append(slice, itemValue.Convert(elemType))
+		default:
+			return reflect.Value{}, fmt.Errorf("cannot convert element %d of type %T to %v", i, item, elemType)
+		}
+	}
+
+	return slice, nil
+}
+
 // FromNativeMap converts a native map to a jsonchamp Map.
 func FromNativeMap(in map[string]any) *Map {
 	res := New()
@@ -190,6 +222,8 @@ func toNativeSlice(in []any) []any {
 			res = append(res, normalizeNativeMap(t))
 		case []any:
 			res = append(res, toNativeSlice(t))
+		case *List:
+			res = append(res, toNativeSlice(t.Values()))
 		case *Map:
 			res = append(res, ToNativeMap(t))
 		default:
@@ -206,13 +240,11 @@ func ToNativeMap(in *Map) map[string]any {
 
 	for _, k := range in.Keys() {
 		v, _ := in.Get(k)
-		switch reflect.TypeOf(v).Kind() {
-		case reflect.Slice:
-			sl := toNativeSlice(normalizeSlice(v))
-			res[k] = sl
-		case mapType.Kind():
-			m, _ := v.(*Map)
-			res[k] = ToNativeMap(m)
+		switch t := v.(type) {
+		case *List:
+			res[k] = toNativeSlice(t.Values())
+		case *Map:
+			res[k] = ToNativeMap(t)
 		default:
 			res[k] = v
 		}
@@ -1,21 +1,21 @@
 package jsonchamp
 
+// cowSlice is the immutable slice of nodes in a trie node. Set, Insert and Delete always return a copy and
+// never write to the receiver, so a slice can be shared by nodes of any number of versions, and read and
+// updated from several goroutines without locking.
 type cowSlice struct {
-	slice  []node
-	shared bool
+	slice []node
 }
 
 func newCowSlice() *cowSlice {
 	return &cowSlice{
-		slice:  nil,
-		shared: false,
+		slice: nil,
 	}
 }
 
 func newCowSliceWithItems(items ...node) *cowSlice {
 	return &cowSlice{
-		slice:  items,
-		shared: false,
+		slice: items,
 	}
 }
 
@@ -32,52 +32,32 @@ func (c *cowSlice) Get(i int) node {
 }
 
 func (c *cowSlice) Set(i int, v node) *cowSlice {
-	if c.shared {
-		n := make([]node, len(c.slice))
-		copy(n, c.slice)
-		n[i] = v
-		return &cowSlice{
-			slice:  n,
-			shared: false,
-		}
-	}
+	n := make([]node, len(c.slice))
+	copy(n, c.slice)
+	n[i] = v
 
-	c.slice[i] = v
-	return c
+	return &cowSlice{
+		slice: n,
+	}
 }
 
 func (c *cowSlice) Insert(i int, v node) *cowSlice {
-	if c.shared {
-		n := make([]node, len(c.slice)+1)
-		copy(n[:i], c.slice[:i])
-		copy(n[i+1:], c.slice[i:])
-		n[i] = v
-		return &cowSlice{
-			slice:  n,
-			shared: false,
-		}
-	}
+	n := make([]node, len(c.slice)+1)
+	copy(n[:i], c.slice[:i])
+	copy(n[i+1:], c.slice[i:])
+	n[i] = v
 
-	c.slice = append(c.slice[:i], append([]node{v}, c.slice[i:]...)...)
-	return c
+	return &cowSlice{
+		slice: n,
+	}
 }
 
 func (c *cowSlice) Delete(i int) *cowSlice {
-	if c.shared {
-		newData := make([]node, len(c.slice))
-		copy(newData[:i], c.slice[:i])
-		copy(newData[i:], c.slice[i+1:])
-		return &cowSlice{
-			slice:  newData,
-			shared: false,
-		}
-	}
-
-	c.slice = append(c.slice[:i], c.slice[i+1:]...)
-	return c
-}
+	n := make([]node, len(c.slice)-1)
+	copy(n[:i], c.slice[:i])
+	copy(n[i:], c.slice[i+1:])
 
-func (c *cowSlice) Share() *cowSlice {
-	c.shared = true
-	return c
+	return &cowSlice{
+		slice: n,
+	}
 }
@@ -14,3 +14,20 @@ func TestSet(t *testing.T) {
 		t.Fatal("expects length to be 1")
 	}
 }
+
+func TestCowSliceDoesNotModifyReceiver(t *testing.T) {
+	first, second := &value{}, &value{}
+	s := newCowSliceWithItems(first)
+
+	if s.Set(0, second); s.Get(0) != first {
+		t.Fatal("expects Set to leave the receiver unchanged")
+	}
+
+	if s.Insert(0, second); s.Len() != 1 {
+		t.Fatal("expects Insert to leave the receiver unchanged")
+	}
+
+	if deleted := s.Delete(0); deleted.Len() != 0 || s.Len() != 1 {
+		t.Fatal("expects Delete to return a shorter copy")
+	}
+}
@@ -0,0 +1,217 @@
+package jsonchamp
+
+import (
+	"fmt"
+	goSlices "slices"
+)
+
+// Cursor is a position in a map that can move through nested maps and arrays and edit the value it points at.
+// Cursors are immutable: every move and edit returns a new cursor. Edits only change the focused value, and
+// the maps and arrays above it are rebuilt when the cursor moves up, so a batch of edits in the same place
+// rebuilds the path to the root once.
+type Cursor struct {
+	focus   any
+	parent  *Cursor
+	segment PathSegment
+	// changed is true if the focus differs from the value at the segment in the parent.
+	changed bool
+	// siblings are the sorted keys of the parent map and position is the index of the segment in them.
+	// They are set by Next, so moving through a map sorts its keys once.
+	siblings []string
+	position int
+}
+
+// NewCursor returns a cursor at the root of the map.
+func NewCursor(m *Map) *Cursor {
+	return &Cursor{focus: m, parent: nil, segment: KeySegment(""), changed: false, siblings: nil, position: 0}
+}
+
+// Value returns the focused value.
+func (c *Cursor) Value() any {
+	return c.focus
+}
+
+// Path returns the path from the root to the focused value.
+func (c *Cursor) Path() Path {
+	var path Path
+
+	for current := c; current.parent != nil; current = current.parent {
+		path = append(path, current.segment)
+	}
+
+	goSlices.Reverse(path)
+
+	return path
+}
+
+func (c *Cursor) child(segment PathSegment, focus any) *Cursor {
+	return &Cursor{focus: focus, parent: c, segment: segment, changed: false, siblings: nil, position: 0}
+}
+
+// Down moves to the value of a key in the focused map.
+func (c *Cursor) Down(key string) (*Cursor, error) {
+	m, ok := c.focus.(*Map)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected map at '%s', got %T", ErrWrongType, c.Path(), c.focus)
+	}
+
+	v, ok := m.Get(key)
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrKeyNotFound, c.Path().Child(key))
+	}
+
+	return c.child(KeySegment(key), v), nil
+}
+
+// Index moves to an element of the focused array.
+func (c *Cursor) Index(i int) (*Cursor, error) {
+	l, ok := c.focus.(*List)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected array at '%s', got %T", ErrWrongType, c.Path(), c.focus)
+	}
+
+	v, ok := l.Get(i)
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrKeyNotFound, c.Path().Index(i))
+	}
+
+	return c.child(IndexSegment(i), v), nil
+}
+
+// Up moves to the map or array holding the focused value, applying the edits made below it.
+// It returns false at the root.
+func (c *Cursor) Up() (*Cursor, bool) {
+	if c.parent == nil {
+		return c, false
+	}
+
+	if !c.changed {
+		return c.parent, true
+	}
+
+	parent := *c.parent
+	parent.focus = withChild(parent.focus, c.segment, c.focus)
+	parent.changed = true
+
+	return &parent, true
+}
+
+// Next moves to the next key of the parent map, in sorted order, or to the next element of the parent array.
+// The keys of the parent map are sorted on the first move and reused by the cursors Next returns.
+// It returns false if the focused value is the last one or the root.
+func (c *Cursor) Next() (*Cursor, bool) {
+	parent, ok := c.Up()
+	if !ok {
+		return c, false
+	}
+
+	switch t := parent.focus.(type) {
+	case *Map:
+		keys, position := c.siblings, c.position
+		if keys == nil {
+			keys = goSlices.Sorted(goSlices.Values(t.Keys()))
+			position, _ = goSlices.BinarySearch(keys, c.segment.Key)
+		}
+
+		if position+1 >= len(keys) {
+			return c, false
+		}
+
+		next, _ := parent.Down(keys[position+1])
+		next.siblings = keys
+		next.position = position + 1
+
+		return next, true
+	case *List:
+		next, err := parent.Index(c.segment.Index + 1)
+		if err != nil {
+			return c, false
+		}
+
+		return next, true
+	default:
+		panic(fmt.Sprintf("cursor parent is not a map or array: %T", parent.focus))
+	}
+}
+
+// Set sets a key in the focused map.
+func (c *Cursor) Set(key string, value any) (*Cursor, error) {
+	m, ok := c.focus.(*Map)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected map at '%s', got %T", ErrWrongType, c.Path(), c.focus)
+	}
+
+	return c.Replace(m.Set(key, value))
+}
+
+// Replace replaces the focused value. The root can only be replaced by a map.
+func (c *Cursor) Replace(value any) (*Cursor, error) {
+	value = normalizeValue(value)
+
+	if _, ok := value.(*Map); !ok && c.parent == nil {
+		return nil, fmt.Errorf("%w: the root must be a map, got %T", ErrWrongType, value)
+	}
+
+	return &Cursor{
+		focus:    value,
+		parent:   c.parent,
+		segment:  c.segment,
+		changed:  true,
+		siblings: c.siblings,
+		position: c.position,
+	}, nil
+}
+
+// Delete removes the focused value from its map or array and moves to the parent.
+// Later elements of an array move down one index, so they are copied, while the elements before
+// the deleted one are shared. The root cannot be deleted.
+func (c *Cursor) Delete() (*Cursor, error) {
+	if c.parent == nil {
+		return nil, fmt.Errorf("%w: cannot delete the root", ErrInvalidPath)
+	}
+
+	parent := *c.parent
+	parent.focus = withoutChild(parent.focus, c.segment)
+	parent.changed = true
+
+	return &parent, nil
+}
+
+// Root moves up to the root and returns the edited map.
+func (c *Cursor) Root() *Map {
+	current := c
+	for ok := true; ok; {
+		current, ok = current.Up()
+	}
+
+	m, ok := current.focus.(*Map)
+	if !ok {
+		panic(fmt.Sprintf("cursor root is not a map: %T", current.focus))
+	}
+
+	return m
+}
+
+func withChild(container any, segment PathSegment, v any) any {
+	switch t := container.(type) {
+	case *Map:
+		return t.Set(segment.Key, v)
+	case *List:
+		return t.Set(segment.Index, v)
+	default:
+		panic(fmt.Sprintf("cursor parent is not a map or array: %T", container))
+	}
+}
+
+func withoutChild(container any, segment PathSegment) any {
+	switch t := container.(type) {
+	case *Map:
+		m, _ := t.Delete(segment.Key)
+
+		return m
+	case *List:
+		return t.without(segment.Index)
+	default:
+		panic(fmt.Sprintf("cursor parent is not a map or array: %T", container))
+	}
+}
@@ -0,0 +1,162 @@
+package jsonchamp
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestCursorEdits(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":{"b":{"c":1,"d":2},"list":[1,2,3]},"other":{"x":1}}`)
+
+	c := NewCursor(m)
+
+	c, err := c.Down("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := c.Down("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, _ = b.Set("c", 10)
+	b, _ = b.Set("e", 3)
+
+	c, _ = b.Up()
+
+	list, err := c.Down("list")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := list.Index(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := second.Path().String(); got != "a.list.1" {
+		t.Errorf("Path() = %s, want a.list.1", got)
+	}
+
+	second, _ = second.Replace("two")
+
+	third, ok := second.Next()
+	if !ok || third.Value() != int64(3) {
+		t.Fatalf("Next() = %v, %v, want 3, true", third.Value(), ok)
+	}
+
+	if _, ok := third.Next(); ok {
+		t.Errorf("expected no element after the last one")
+	}
+
+	afterDelete, err := third.Delete()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := afterDelete.Root()
+
+	want := mustParse(t, `{"a":{"b":{"c":10,"d":2,"e":3},"list":[1,"two"]},"other":{"x":1}}`)
+	if !got.Equals(want) {
+		t.Errorf("Root() = %v, want %v", got.ToMap(), want.ToMap())
+	}
+
+	if original := mustParse(t, `{"a":{"b":{"c":1,"d":2},"list":[1,2,3]},"other":{"x":1}}`); !m.Equals(original) {
+		t.Errorf("original map was modified: %v", m.ToMap())
+	}
+
+	before, _ := m.GetMap("other")
+	after, _ := got.GetMap("other")
+
+	if before != after {
+		t.Errorf("expected untouched branch to be shared")
+	}
+}
+
+func TestCursorNextInMap(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"c":3,"a":1,"b":2}`)
+
+	c, _ := NewCursor(m).Down("a")
+
+	var keys []string
+
+	for ok := true; ok; c, ok = c.Next() {
+		keys = append(keys, c.Path().String())
+	}
+
+	if len(keys) != 3 || keys[0] != "a" || keys[1] != "b" || keys[2] != "c" {
+		t.Errorf("visited %v, want [a b c]", keys)
+	}
+
+	if root := NewCursor(m).Root(); root != m {
+		t.Errorf("expected Root without edits to return the original map")
+	}
+}
+
+func TestCursorNextWithEdits(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"d":{"x":4},"b":2,"a":1,"c":3}`)
+
+	c, _ := NewCursor(m).Down("a")
+
+	var keys []string
+
+	for ok := true; ok; c, ok = c.Next() {
+		keys = append(keys, c.Path().String())
+
+		if inner, err := c.Down("x"); err == nil {
+			inner, _ = inner.Replace(40)
+			c, _ = inner.Up()
+		} else {
+			c, _ = c.Replace(fmt.Sprintf("%v!", c.Value()))
+		}
+	}
+
+	if want := []string{"a", "b", "c", "d"}; !slices.Equal(keys, want) {
+		t.Errorf("visited %v, want %v", keys, want)
+	}
+
+	want := mustParse(t, `{"a":"1!","b":"2!","c":"3!","d":{"x":40}}`)
+	if got := c.Root(); !got.Equals(want) {
+		t.Errorf("Root() = %v, want %v", got, want)
+	}
+}
+
+func TestCursorErrors(t *testing.T) {
+	t.Parallel()
+
+	c := NewCursor(mustParse(t, `{"a":1,"l":[]}`))
+
+	if _, err := c.Down("missing"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Down() error = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	if _, err := c.Index(0); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Index() error = %v, want %v", err, ErrWrongType)
+	}
+
+	if _, err := c.Delete(); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Delete() error = %v, want %v", err, ErrInvalidPath)
+	}
+
+	if _, err := c.Replace(1); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Replace() error = %v, want %v", err, ErrWrongType)
+	}
+
+	a, _ := c.Down("a")
+	if _, err := a.Set("b", 1); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Set() error = %v, want %v", err, ErrWrongType)
+	}
+
+	if _, ok := c.Up(); ok {
+		t.Errorf("expected Up at the root to return false")
+	}
+}
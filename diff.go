@@ -7,8 +7,9 @@ import (
 
 var (
 	mapType = reflect.TypeOf(&Map{
-		root:   nil,
-		hasher: nil,
+		root:     nil,
+		hasher:   nil,
+		interner: nil,
 	})
 )
 
@@ -25,6 +26,16 @@ func normalizeSlice(in any) []any {
 	return result
 }
 
+// normalizeList normalizes the elements of a slice and stores them in a List.
+func normalizeList(in []any) *List {
+	normalized := make([]any, len(in))
+	for i, v := range in {
+		normalized[i] = normalizeValue(v)
+	}
+
+	return newListFromValues(normalized)
+}
+
 func normalizeNativeMap(in map[string]any) map[string]any {
 	out := make(map[string]any, len(in))
 
@@ -39,6 +50,8 @@ func normalizeNativeMap(in map[string]any) map[string]any {
 			}
 
 			out[k] = arr
+		case []any:
+			out[k] = toNativeSlice(t)
 		default:
 			out[k] = normalizeValue(t)
 		}
@@ -48,8 +61,15 @@ func normalizeNativeMap(in map[string]any) map[string]any {
 }
 
 func normalizeValue(in any) any {
-	if in == nil {
+	switch v := in.(type) {
+	case nil:
 		return nil
+	case *Map:
+		return v
+	case *List:
+		return v
+	case []any:
+		return normalizeList(v)
 	}
 
 	t := reflect.TypeOf(in)
@@ -58,8 +78,10 @@ func normalizeValue(in any) any {
 		return reflect.ValueOf(in).Int()
 	case reflect.Float32, reflect.Float64:
 		return reflect.ValueOf(in).Float()
+	case reflect.Bool:
+		return reflect.ValueOf(in).Bool()
 	case reflect.Slice:
-		return normalizeSlice(in)
+		return normalizeList(normalizeSlice(in))
 	case reflect.String:
 		stringed, ok := in.(string)
 		if !ok {
@@ -89,6 +111,10 @@ func normalizeValue(in any) any {
 func diffMap(m *Map, other *Map) *Map {
 	diff := New()
 
+	if equal, known := knownEqual(m, other); known && equal {
+		return diff
+	}
+
 	oneKeys := m.Keys()
 	otherKeys := other.Keys()
 	unionKeys := union(oneKeys, otherKeys)
@@ -146,15 +172,17 @@ func diffMap(m *Map, other *Map) *Map {
 			if oneFloat != otherFloat {
 				diff = diff.Set(k, otherFloat)
 			}
-		case []any:
-			oneSlice, otherSlice := castPair[[]any](oneValue, otherValue)
-			slicesAreEqual := equalsAnyList(oneSlice, otherSlice)
+		case *List:
+			oneList, otherList := castPair[*List](oneValue, otherValue)
 
-			if !slicesAreEqual {
-				diff = diff.Set(k, otherSlice)
+			if !oneList.Equals(otherList) {
+				diff = diff.Set(k, otherList)
 			}
 		default:
-			panic(fmt.Sprintf("Unhandled type %T", oneValue))
+			// Booleans, nulls and other values without a case above are compared like in Equals.
+			if !equalsAny(oneValue, otherValue) {
+				diff = diff.Set(k, otherValue)
+			}
 		}
 	}
 
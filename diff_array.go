@@ -0,0 +1,269 @@
+package jsonchamp
+
+// EditKind is the kind of an edit between two arrays.
+type EditKind int
+
+const (
+	// EditInsert means an element was inserted in the new array.
+	EditInsert EditKind = iota
+	// EditDelete means an element was removed from the old array.
+	EditDelete
+	// EditModify means an element was replaced by a different element at the same aligned position.
+	EditModify
+)
+
+// String returns the name of the edit kind.
+func (k EditKind) String() string {
+	switch k {
+	case EditInsert:
+		return "insert"
+	case EditDelete:
+		return "delete"
+	case EditModify:
+		return "modify"
+	default:
+		return "unknown"
+	}
+}
+
+// ArrayEdit is a single index-level edit between two arrays.
+type ArrayEdit struct {
+	Kind EditKind
+	// OldIndex is the index in the old array, or -1 for insertions.
+	OldIndex int
+	// NewIndex is the index in the new array, or -1 for deletions.
+	NewIndex int
+	// Old is the element in the old array. It is nil for insertions.
+	Old any
+	// New is the element in the new array. It is nil for deletions.
+	New any
+}
+
+// DiffArrays returns the index-level edits that turn a into b.
+// Elements are aligned with a shortest edit script, found with Myers' algorithm in linear space, so inserting
+// a single element into a large array produces a single insertion. It takes O((n+m)d) time, where d is the
+// number of inserted and deleted elements. Deletions and insertions between the same aligned elements are paired up as
+// modifications. The edits are ordered by position.
+func DiffArrays(a []any, b []any) []ArrayEdit {
+	return editsFromSteps(alignSteps(anySlice(a), anySlice(b)), anySlice(a), anySlice(b))
+}
+
+// DiffLists is like DiffArrays, but reads the elements from the lists without copying them to slices.
+func DiffLists(a *List, b *List) []ArrayEdit {
+	return editsFromSteps(alignSteps(a, b), a, b)
+}
+
+// sequence is an array whose elements can be aligned by alignSteps.
+type sequence interface {
+	Len() int
+	at(i int) any
+}
+
+type anySlice []any
+
+func (s anySlice) Len() int {
+	return len(s)
+}
+
+func (s anySlice) at(i int) any {
+	return s[i]
+}
+
+type lcsStep struct {
+	kind     EditKind
+	matched  bool
+	oldIndex int
+	newIndex int
+}
+
+// alignSteps aligns two arrays and returns one step per aligned pair, deleted element or inserted element.
+func alignSteps(a sequence, b sequence) []lcsStep {
+	al := aligner{a: a, b: b, steps: make([]lcsStep, 0, max(a.Len(), b.Len()))}
+	al.align(0, a.Len(), 0, b.Len())
+
+	return al.steps
+}
+
+// aligner finds a shortest edit script between two arrays by recursively splitting them where an optimal path
+// from the start meets an optimal path from the end, as described in "An O(ND) Difference Algorithm and
+// Its Variations" by Eugene W. Myers.
+type aligner struct {
+	a     sequence
+	b     sequence
+	steps []lcsStep
+}
+
+// align appends the steps that turn a[aLo:aHi] into b[bLo:bHi].
+func (al *aligner) align(aLo int, aHi int, bLo int, bHi int) {
+	// Common prefixes and suffixes are always part of a shortest edit script.
+	for aLo < aHi && bLo < bHi && al.equal(aLo, bLo) {
+		al.steps = append(al.steps, lcsStep{kind: EditModify, matched: true, oldIndex: aLo, newIndex: bLo})
+		aLo++
+		bLo++
+	}
+
+	suffix := 0
+	for aLo < aHi-suffix && bLo < bHi-suffix && al.equal(aHi-1-suffix, bHi-1-suffix) {
+		suffix++
+	}
+
+	aHi -= suffix
+	bHi -= suffix
+
+	switch {
+	case aLo == aHi:
+		for j := bLo; j < bHi; j++ {
+			al.steps = append(al.steps, lcsStep{kind: EditInsert, matched: false, oldIndex: -1, newIndex: j})
+		}
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			al.steps = append(al.steps, lcsStep{kind: EditDelete, matched: false, oldIndex: i, newIndex: -1})
+		}
+	default:
+		x, y := al.split(aLo, aHi, bLo, bHi)
+		al.align(aLo, x, bLo, y)
+		al.align(x, aHi, y, bHi)
+	}
+
+	for k := range suffix {
+		al.steps = append(al.steps, lcsStep{kind: EditModify, matched: true, oldIndex: aHi + k, newIndex: bHi + k})
+	}
+}
+
+// split returns a point on a shortest edit script of a[aLo:aHi] and b[bLo:bHi], found by searching from both
+// ends until the paths overlap. Both ranges are non-empty, so the point splits them into smaller problems.
+func (al *aligner) split(aLo int, aHi int, bLo int, bHi int) (int, int) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD + 1
+
+	// forward[offset+k] is the furthest x reached from the start on diagonal k = x - y, and backward[offset+k]
+	// the furthest x reached from the end on diagonal k of the reversed arrays. -1 means not reached yet.
+	forward := make([]int, 2*offset+1)
+	backward := make([]int, 2*offset+1)
+
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// If delta is odd, the paths overlap during a forward step, otherwise during a backward step.
+	odd := delta%2 != 0
+
+	// Diagonals whose paths left the ranges are skipped.
+	var forwardStart, forwardEnd, backwardStart, backwardEnd int
+
+	for d := 0; d <= maxD; d++ {
+		for k := -d + forwardStart; k <= d-forwardEnd; k += 2 {
+			x := nextX(forward, offset, k, d)
+			y := x - k
+
+			for x < n && y < m && al.equal(aLo+x, bLo+y) {
+				x++
+				y++
+			}
+
+			forward[offset+k] = x
+
+			switch reverse := offset + delta - k; {
+			case x > n:
+				forwardEnd += 2
+			case y > m:
+				forwardStart += 2
+			case odd && reverse >= 0 && reverse < len(backward) && backward[reverse] != -1 && x >= n-backward[reverse]:
+				return aLo + x, bLo + y
+			}
+		}
+
+		for k := -d + backwardStart; k <= d-backwardEnd; k += 2 {
+			x := nextX(backward, offset, k, d)
+			y := x - k
+
+			for x < n && y < m && al.equal(aHi-1-x, bHi-1-y) {
+				x++
+				y++
+			}
+
+			backward[offset+k] = x
+
+			switch ahead := offset + delta - k; {
+			case x > n:
+				backwardEnd += 2
+			case y > m:
+				backwardStart += 2
+			case !odd && ahead >= 0 && ahead < len(forward) && forward[ahead] != -1 && forward[ahead] >= n-x:
+				forwardX := forward[ahead]
+
+				return aLo + forwardX, bLo + forwardX - (delta - k)
+			}
+		}
+	}
+
+	// The paths always overlap within maxD steps.
+	panic("array alignment did not converge")
+}
+
+// nextX returns the x where a path with d edits on diagonal k starts, extending the furthest path of
+// a neighbouring diagonal with an insertion or a deletion.
+func nextX(v []int, offset int, k int, d int) int {
+	if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+		return v[offset+k+1]
+	}
+
+	return v[offset+k-1] + 1
+}
+
+func (al *aligner) equal(i int, j int) bool {
+	return equalsAny(al.a.at(i), al.b.at(j))
+}
+
+// editsFromSteps pairs up the deletions and insertions between matched elements as modifications.
+func editsFromSteps(steps []lcsStep, a sequence, b sequence) []ArrayEdit {
+	var edits []ArrayEdit
+
+	var deleted, inserted []int
+
+	flush := func() {
+		paired := min(len(deleted), len(inserted))
+
+		for k := range paired {
+			edits = append(edits, ArrayEdit{
+				Kind:     EditModify,
+				OldIndex: deleted[k],
+				NewIndex: inserted[k],
+				Old:      a.at(deleted[k]),
+				New:      b.at(inserted[k]),
+			})
+		}
+
+		for _, i := range deleted[paired:] {
+			edits = append(edits, ArrayEdit{Kind: EditDelete, OldIndex: i, NewIndex: -1, Old: a.at(i), New: nil})
+		}
+
+		for _, j := range inserted[paired:] {
+			edits = append(edits, ArrayEdit{Kind: EditInsert, OldIndex: -1, NewIndex: j, Old: nil, New: b.at(j)})
+		}
+
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+
+	for _, step := range steps {
+		switch {
+		case step.matched:
+			flush()
+		case step.kind == EditModify:
+			deleted = append(deleted, step.oldIndex)
+			inserted = append(inserted, step.newIndex)
+		case step.kind == EditDelete:
+			deleted = append(deleted, step.oldIndex)
+		default:
+			inserted = append(inserted, step.newIndex)
+		}
+	}
+
+	flush()
+
+	return edits
+}
@@ -0,0 +1,197 @@
+package jsonchamp
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestDiffArrays(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a    []any
+		b    []any
+		want []ArrayEdit
+	}{
+		{
+			name: "equal arrays",
+			a:    []any{int64(1), int64(2)},
+			b:    []any{int64(1), int64(2)},
+			want: nil,
+		},
+		{
+			name: "insertion in the middle",
+			a:    []any{"a", "b", "c"},
+			b:    []any{"a", "x", "b", "c"},
+			want: []ArrayEdit{{Kind: EditInsert, OldIndex: -1, NewIndex: 1, New: "x"}},
+		},
+		{
+			name: "deletion at the start",
+			a:    []any{"a", "b", "c"},
+			b:    []any{"b", "c"},
+			want: []ArrayEdit{{Kind: EditDelete, OldIndex: 0, NewIndex: -1, Old: "a"}},
+		},
+		{
+			name: "modification",
+			a:    []any{"a", "b", "c"},
+			b:    []any{"a", "x", "c"},
+			want: []ArrayEdit{{Kind: EditModify, OldIndex: 1, NewIndex: 1, Old: "b", New: "x"}},
+		},
+		{
+			name: "mixed edits",
+			a:    []any{"a", "b", "c", "d"},
+			b:    []any{"x", "b", "d", "e"},
+			want: []ArrayEdit{
+				{Kind: EditModify, OldIndex: 0, NewIndex: 0, Old: "a", New: "x"},
+				{Kind: EditDelete, OldIndex: 2, NewIndex: -1, Old: "c"},
+				{Kind: EditInsert, OldIndex: -1, NewIndex: 3, New: "e"},
+			},
+		},
+		{
+			name: "empty old array",
+			a:    nil,
+			b:    []any{"a"},
+			want: []ArrayEdit{{Kind: EditInsert, OldIndex: -1, NewIndex: 0, New: "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := DiffArrays(tt.a, tt.b)
+			if !slices.EqualFunc(got, tt.want, equalArrayEdit) {
+				t.Errorf("DiffArrays() = %+v, want %+v", got, tt.want)
+			}
+
+			if got := DiffLists(NewList(tt.a...), NewList(tt.b...)); !slices.EqualFunc(got, tt.want, equalArrayEdit) {
+				t.Errorf("DiffLists() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffArraysLargeInsertion(t *testing.T) {
+	t.Parallel()
+
+	a := make([]any, 0, 5000)
+	for i := range 5000 {
+		a = append(a, int64(i))
+	}
+
+	b := slices.Insert(slices.Clone(a), 2500, any("inserted"))
+
+	edits := DiffArrays(a, b)
+	if len(edits) != 1 || edits[0].Kind != EditInsert || edits[0].NewIndex != 2500 {
+		t.Fatalf("expected a single insertion at index 2500, got %+v", edits)
+	}
+
+	edits = DiffLists(NewList(a...), NewList(b...))
+	if len(edits) != 1 || edits[0].Kind != EditInsert || edits[0].NewIndex != 2500 || edits[0].New != "inserted" {
+		t.Fatalf("expected a single list insertion at index 2500, got %+v", edits)
+	}
+}
+
+func TestDiffArraysLargeInsertionAndModification(t *testing.T) {
+	t.Parallel()
+
+	a := make([]any, 0, 5000)
+	for i := range 5000 {
+		a = append(a, int64(i))
+	}
+
+	b := slices.Insert(slices.Clone(a), 0, any("first"))
+	b[2501] = "changed"
+
+	edits := DiffLists(NewList(a...), NewList(b...))
+	if len(edits) != 2 || edits[0].Kind != EditInsert || edits[1].Kind != EditModify || edits[1].NewIndex != 2501 {
+		t.Fatalf("expected an insertion and a modification, got %d edits: %+v", len(edits), edits[:min(len(edits), 4)])
+	}
+}
+
+func TestDiffArraysShortestEditScript(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewPCG(3, 4))
+
+	for range 2000 {
+		a := randomArray(rng, rng.IntN(30))
+		b := randomArray(rng, rng.IntN(30))
+
+		steps := alignSteps(anySlice(a), anySlice(b))
+
+		matched, i, j := 0, 0, 0
+
+		for _, step := range steps {
+			switch {
+			case step.matched:
+				if step.oldIndex != i || step.newIndex != j || !equalsAny(a[i], b[j]) {
+					t.Fatalf("invalid match %+v at %d, %d aligning %v and %v", step, i, j, a, b)
+				}
+
+				matched++
+				i++
+				j++
+			case step.kind == EditDelete:
+				i++
+			default:
+				j++
+			}
+		}
+
+		if i != len(a) || j != len(b) {
+			t.Fatalf("steps cover %d and %d elements of %v and %v", i, j, a, b)
+		}
+
+		if want := lcsLength(a, b); matched != want {
+			t.Fatalf("matched %d elements of %v and %v, want %d", matched, a, b, want)
+		}
+	}
+}
+
+func randomArray(rng *rand.Rand, n int) []any {
+	values := make([]any, n)
+	for i := range values {
+		values[i] = rng.Int64N(4)
+	}
+
+	return values
+}
+
+// lcsLength returns the length of the longest common subsequence with a quadratic table.
+func lcsLength(a []any, b []any) int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if equalsAny(a[i], b[j]) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	return lengths[0][0]
+}
+
+func TestChangesAlignsArrays(t *testing.T) {
+	t.Parallel()
+
+	got := Changes(mustParse(t, `{"a":[{"id":1},{"id":2}]}`), mustParse(t, `{"a":[{"id":0},{"id":1},{"id":2}]}`))
+	want := []Change{{Path: MustParsePath("a.0"), Kind: ChangeAdded, New: mustParse(t, `{"id":0}`)}}
+
+	if !slices.EqualFunc(got, want, equalChange) {
+		t.Errorf("Changes() = %+v, want %+v", got, want)
+	}
+}
+
+func equalArrayEdit(a ArrayEdit, b ArrayEdit) bool {
+	return a.Kind == b.Kind && a.OldIndex == b.OldIndex && a.NewIndex == b.NewIndex &&
+		equalsAny(a.Old, b.Old) && equalsAny(a.New, b.New)
+}
@@ -0,0 +1,418 @@
+package jsonchamp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	defaultFlattenSeparator = "."
+	flattenEscape           = '\\'
+)
+
+// IndexNotation decides how array indices are written in flattened keys.
+type IndexNotation int
+
+const (
+	// IndexDots writes indices as separate segments, like a.0.b.
+	// Object keys that consist of digits only are escaped, like a.\0.b, so they are not read back as indices.
+	IndexDots IndexNotation = iota
+	// IndexBrackets writes indices in brackets, like a[0].b.
+	IndexBrackets
+)
+
+type flattenOptions struct {
+	separator string
+	indices   IndexNotation
+	// anyIndices reads both digit segments and brackets as indices, and escapes keys that could be read as either.
+	// It is used for the string syntax of a Path, which unlike a flattened key can start with an index.
+	anyIndices bool
+	// err is set by an invalid option and returned by Flatten and Unflatten.
+	err error
+}
+
+// FlattenOption is a function that sets an option on Flatten and Unflatten.
+type FlattenOption func(*flattenOptions)
+
+// WithSeparator sets the separator between the keys of a flattened path. The default is ".".
+// The separator must not be empty or contain a backslash or a bracket, otherwise Flatten and Unflatten
+// return an error wrapping ErrInvalidPath.
+func WithSeparator(separator string) FlattenOption {
+	return func(o *flattenOptions) {
+		if separator == "" || strings.ContainsAny(separator, `\[]`) {
+			o.err = fmt.Errorf("%w: invalid flatten separator %q", ErrInvalidPath, separator)
+
+			return
+		}
+
+		o.separator = separator
+	}
+}
+
+// WithIndexNotation sets how array indices are written. The default is IndexDots.
+func WithIndexNotation(notation IndexNotation) FlattenOption {
+	return func(o *flattenOptions) {
+		o.indices = notation
+	}
+}
+
+func newFlattenOptions(opts []FlattenOption) (flattenOptions, error) {
+	options := flattenOptions{separator: defaultFlattenSeparator, indices: IndexDots, anyIndices: false, err: nil}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options, options.err
+}
+
+// Flatten returns the leaf values of a map keyed by their paths, like {"a.b": 1, "a.c.0": true}.
+// Empty maps and arrays are kept as empty map[string]any and []any values, and keys containing the separator,
+// brackets or backslashes are escaped with a backslash, so Unflatten restores the exact same map.
+// It returns ErrInvalidPath if an option is invalid.
+func Flatten(m *Map, opts ...FlattenOption) (map[string]any, error) {
+	options, err := newFlattenOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	flat := make(map[string]any)
+
+	Walk(m, func(path Path, v any) WalkAction {
+		switch t := v.(type) {
+		case *Map:
+			if t.Len() == 0 {
+				flat[options.format(path)] = map[string]any{}
+			}
+		case *List:
+			if t.Len() == 0 {
+				flat[options.format(path)] = []any{}
+			}
+		default:
+			flat[options.format(path)] = v
+		}
+
+		return WalkContinue
+	})
+
+	return flat, nil
+}
+
+// Unflatten builds a nested map from flattened paths, as returned by Flatten with the same options.
+// It returns ErrInvalidPath if an option is invalid, if a key cannot be parsed, if a path is both a value
+// and a parent of other values, or if the indices of an array are not contiguous from zero.
+func Unflatten(flat map[string]any, opts ...FlattenOption) (*Map, error) {
+	options, err := newFlattenOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	root := newFlatNode()
+
+	for flatKey, v := range flat {
+		path, err := options.parse(flatKey)
+		if err != nil {
+			return nil, err
+		}
+
+		// Flatten returns empty maps as native maps, which Set would store as they are.
+		if native, ok := v.(map[string]any); ok {
+			v = FromNativeMap(native)
+		}
+
+		if err := root.insert(path, normalizeValue(v)); err != nil {
+			return nil, fmt.Errorf("%w: '%s'", err, flatKey)
+		}
+	}
+
+	built, err := root.build()
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := built.(*Map)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected object at the root", ErrInvalidPath)
+	}
+
+	return m, nil
+}
+
+// format writes a path as a flattened key.
+func (o flattenOptions) format(path Path) string {
+	var b strings.Builder
+
+	for i, segment := range path {
+		// A bracket at the start of a Path is read as a leading index, so an index after a leading empty key is
+		// written as a digit segment instead.
+		leadingEmptyKey := o.anyIndices && i == 1 && path[0] == KeySegment("")
+
+		if segment.IsIndex && o.indices == IndexBrackets && !leadingEmptyKey {
+			b.WriteString("[" + strconv.Itoa(segment.Index) + "]")
+
+			continue
+		}
+
+		if i > 0 {
+			b.WriteString(o.separator)
+		}
+
+		switch {
+		case segment.IsIndex:
+			b.WriteString(strconv.Itoa(segment.Index))
+		case o.anyIndices && i == 0 && strings.HasPrefix(segment.Key, "/"):
+			// A Path starting with a slash is read as a JSON Pointer.
+			b.WriteRune(flattenEscape)
+			o.writeKey(&b, segment.Key)
+		default:
+			o.writeKey(&b, segment.Key)
+		}
+	}
+
+	return b.String()
+}
+
+// digitIndices returns true if segments of digits are indices.
+func (o flattenOptions) digitIndices() bool {
+	return o.anyIndices || o.indices == IndexDots
+}
+
+// bracketIndices returns true if indices are written in brackets.
+func (o flattenOptions) bracketIndices() bool {
+	return o.anyIndices || o.indices == IndexBrackets
+}
+
+func (o flattenOptions) writeKey(b *strings.Builder, key string) {
+	if _, ok := parseIndex(key); ok && o.digitIndices() {
+		b.WriteRune(flattenEscape)
+	}
+
+	for i := 0; i < len(key); {
+		switch {
+		case strings.HasPrefix(key[i:], o.separator):
+			b.WriteRune(flattenEscape)
+			b.WriteString(o.separator)
+			i += len(o.separator)
+
+			continue
+		case key[i] == flattenEscape, o.bracketIndices() && (key[i] == '[' || key[i] == ']'):
+			b.WriteRune(flattenEscape)
+		}
+
+		b.WriteByte(key[i])
+		i++
+	}
+}
+
+// parse reads a flattened key into a path.
+func (o flattenOptions) parse(flatKey string) (Path, error) {
+	var (
+		path    Path
+		segment strings.Builder
+		escaped bool
+		// closed is true right after an index in brackets, when there is no pending key segment.
+		closed bool
+	)
+
+	endSegment := func() {
+		if closed {
+			closed = false
+
+			return
+		}
+
+		if index, ok := parseIndex(segment.String()); ok && !escaped && o.digitIndices() {
+			path = append(path, IndexSegment(index))
+		} else {
+			path = append(path, KeySegment(segment.String()))
+		}
+
+		segment.Reset()
+
+		escaped = false
+	}
+
+	for i := 0; i < len(flatKey); {
+		isSeparator := strings.HasPrefix(flatKey[i:], o.separator)
+		isBracket := o.bracketIndices() && flatKey[i] == '['
+
+		if closed && !isSeparator && !isBracket {
+			return nil, fmt.Errorf("%w: expected separator after index at offset %d in '%s'", ErrInvalidPath, i, flatKey)
+		}
+
+		switch {
+		case flatKey[i] == flattenEscape:
+			if i+1 >= len(flatKey) {
+				return nil, fmt.Errorf("%w: trailing escape in '%s'", ErrInvalidPath, flatKey)
+			}
+
+			if strings.HasPrefix(flatKey[i+1:], o.separator) {
+				segment.WriteString(o.separator)
+				i += 1 + len(o.separator)
+			} else {
+				r, size := utf8.DecodeRuneInString(flatKey[i+1:])
+				segment.WriteRune(r)
+				i += 1 + size
+			}
+
+			escaped = true
+		case isSeparator:
+			endSegment()
+
+			i += len(o.separator)
+		case isBracket:
+			// Flattened keys start with a key of the root map, so a leading bracket follows an empty key.
+			if i > 0 || !o.anyIndices {
+				endSegment()
+			}
+
+			end := strings.IndexByte(flatKey[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unclosed bracket at offset %d in '%s'", ErrInvalidPath, i, flatKey)
+			}
+
+			index, ok := parseIndex(flatKey[i+1 : i+end])
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid index at offset %d in '%s'", ErrInvalidPath, i, flatKey)
+			}
+
+			path = append(path, IndexSegment(index))
+			closed = true
+			i += end + 1
+		default:
+			segment.WriteByte(flatKey[i])
+			i++
+		}
+	}
+
+	endSegment()
+
+	return path, nil
+}
+
+// parseIndex parses an array index in canonical decimal form, without sign or leading zeros.
+func parseIndex(s string) (int, bool) {
+	if s == "" || (len(s) > 1 && s[0] == '0') {
+		return 0, false
+	}
+
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+
+	index, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false
+	}
+
+	return index, true
+}
+
+// flatNode is a node of the tree built by Unflatten before it is converted to maps and lists.
+type flatNode struct {
+	keys    map[string]*flatNode
+	indices map[int]*flatNode
+	value   any
+	isLeaf  bool
+}
+
+func newFlatNode() *flatNode {
+	return &flatNode{keys: nil, indices: nil, value: nil, isLeaf: false}
+}
+
+func (n *flatNode) insert(path Path, v any) error {
+	node := n
+
+	for i, segment := range path {
+		child, err := node.child(segment)
+		if err != nil {
+			return fmt.Errorf("%w at %s", err, path[:i])
+		}
+
+		node = child
+	}
+
+	if node.isLeaf || node.keys != nil || node.indices != nil {
+		return fmt.Errorf("%w: %s is set more than once", ErrInvalidPath, path)
+	}
+
+	node.value = v
+	node.isLeaf = true
+
+	return nil
+}
+
+// child returns the child node for the segment, creating it if needed.
+func (n *flatNode) child(segment PathSegment) (*flatNode, error) {
+	switch {
+	case n.isLeaf:
+		return nil, fmt.Errorf("%w: expected object or array, found value", ErrInvalidPath)
+	case segment.IsIndex && n.keys != nil:
+		return nil, fmt.Errorf("%w: expected array, found object", ErrInvalidPath)
+	case !segment.IsIndex && n.indices != nil:
+		return nil, fmt.Errorf("%w: expected object, found array", ErrInvalidPath)
+	}
+
+	if segment.IsIndex {
+		if n.indices == nil {
+			n.indices = make(map[int]*flatNode)
+		}
+
+		if _, ok := n.indices[segment.Index]; !ok {
+			n.indices[segment.Index] = newFlatNode()
+		}
+
+		return n.indices[segment.Index], nil
+	}
+
+	if n.keys == nil {
+		n.keys = make(map[string]*flatNode)
+	}
+
+	if _, ok := n.keys[segment.Key]; !ok {
+		n.keys[segment.Key] = newFlatNode()
+	}
+
+	return n.keys[segment.Key], nil
+}
+
+func (n *flatNode) build() (any, error) {
+	switch {
+	case n.isLeaf:
+		return n.value, nil
+	case n.indices != nil:
+		values := make([]any, len(n.indices))
+
+		for i := range values {
+			child, ok := n.indices[i]
+			if !ok {
+				return nil, fmt.Errorf("%w: missing array index %d", ErrInvalidPath, i)
+			}
+
+			v, err := child.build()
+			if err != nil {
+				return nil, err
+			}
+
+			values[i] = v
+		}
+
+		return newListFromValues(values), nil
+	default:
+		m := New()
+
+		for key, child := range n.keys {
+			v, err := child.build()
+			if err != nil {
+				return nil, err
+			}
+
+			m = m.Set(key, v)
+		}
+
+		return m, nil
+	}
+}
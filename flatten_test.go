@@ -0,0 +1,188 @@
+package jsonchamp
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestFlatten(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":{"b":1,"c":[true,{"d":"x"}]},"e":[],"f":{}}`)
+
+	tests := []struct {
+		name string
+		opts []FlattenOption
+		want map[string]any
+	}{
+		{
+			name: "dots",
+			opts: nil,
+			want: map[string]any{
+				"a.b": int64(1), "a.c.0": true, "a.c.1.d": "x", "e": []any{}, "f": map[string]any{},
+			},
+		},
+		{
+			name: "brackets",
+			opts: []FlattenOption{WithIndexNotation(IndexBrackets)},
+			want: map[string]any{
+				"a.b": int64(1), "a.c[0]": true, "a.c[1].d": "x", "e": []any{}, "f": map[string]any{},
+			},
+		},
+		{
+			name: "separator",
+			opts: []FlattenOption{WithSeparator("/")},
+			want: map[string]any{
+				"a/b": int64(1), "a/c/0": true, "a/c/1/d": "x", "e": []any{}, "f": map[string]any{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := Flatten(m, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Flatten() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlattenEscapesKeys(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a.b":{"0":1,"x\\y":2,"[k]":3,"a__b":4}}`)
+
+	tests := []struct {
+		name string
+		opts []FlattenOption
+		want map[string]any
+	}{
+		{
+			name: "dots",
+			opts: nil,
+			want: map[string]any{
+				`a\.b.\0`: int64(1), `a\.b.x\\y`: int64(2), `a\.b.[k]`: int64(3), `a\.b.a__b`: int64(4),
+			},
+		},
+		{
+			name: "brackets",
+			opts: []FlattenOption{WithIndexNotation(IndexBrackets)},
+			want: map[string]any{
+				`a\.b.0`: int64(1), `a\.b.x\\y`: int64(2), `a\.b.\[k\]`: int64(3), `a\.b.a__b`: int64(4),
+			},
+		},
+		{
+			name: "multi-character separator",
+			opts: []FlattenOption{WithSeparator("__")},
+			want: map[string]any{
+				`a.b__\0`: int64(1), `a.b__x\\y`: int64(2), `a.b__[k]`: int64(3), `a.b__a\__b`: int64(4),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			flat, err := Flatten(m, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(flat, tt.want) {
+				t.Errorf("Flatten() = %v, want %v", flat, tt.want)
+			}
+
+			got, err := Unflatten(flat, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !got.Equals(m) {
+				t.Errorf("Unflatten() = %v, want %v", got.ToMap(), m.ToMap())
+			}
+		})
+	}
+}
+
+func TestFlattenRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	maps := []*Map{
+		mustParse(t, `{"a":{"b":[1,[2,{"":null}],{}]},"":{"7":[]},"x___y":"z"}`),
+		// An array in the empty key is flattened to keys starting with a bracket.
+		mustParse(t, `{"":[1,[2]]}`),
+	}
+
+	for _, m := range maps {
+		for _, opts := range [][]FlattenOption{
+			nil,
+			{WithIndexNotation(IndexBrackets)},
+			{WithSeparator("__")},
+			{WithSeparator("__"), WithIndexNotation(IndexBrackets)},
+		} {
+			flat, err := Flatten(m, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Unflatten(flat, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !got.Equals(m) {
+				t.Errorf("round trip = %v, want %v", got.ToMap(), m.ToMap())
+			}
+		}
+	}
+}
+
+func TestUnflattenErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		flat map[string]any
+		opts []FlattenOption
+	}{
+		{name: "value and parent", flat: map[string]any{"a": 1, "a.b": 2}, opts: nil},
+		{name: "object and array", flat: map[string]any{"a.0": 1, "a.b": 2}, opts: nil},
+		{name: "missing index", flat: map[string]any{"a.0": 1, "a.2": 2}, opts: nil},
+		{name: "array at root", flat: map[string]any{"0": 1}, opts: nil},
+		{name: "trailing escape", flat: map[string]any{`a\`: 1}, opts: nil},
+		{name: "bad index", flat: map[string]any{"a[x]": 1}, opts: []FlattenOption{WithIndexNotation(IndexBrackets)}},
+		{name: "text after index", flat: map[string]any{"a[0]b": 1}, opts: []FlattenOption{WithIndexNotation(IndexBrackets)}},
+		{name: "empty separator", flat: map[string]any{"a": 1}, opts: []FlattenOption{WithSeparator("")}},
+		{name: "separator with escape", flat: map[string]any{"a": 1}, opts: []FlattenOption{WithSeparator(`\`)}},
+		{name: "separator with bracket", flat: map[string]any{"a": 1}, opts: []FlattenOption{WithSeparator("]")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := Unflatten(tt.flat, tt.opts...); !errors.Is(err, ErrInvalidPath) {
+				t.Errorf("Unflatten() error = %v, want %v", err, ErrInvalidPath)
+			}
+		})
+	}
+}
+
+func TestFlattenInvalidSeparator(t *testing.T) {
+	t.Parallel()
+
+	for _, separator := range []string{"", `\`, "[", "]", "a]b"} {
+		if _, err := Flatten(mustParse(t, `{"a":1}`), WithSeparator(separator)); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("Flatten() with separator %q error = %v, want %v", separator, err, ErrInvalidPath)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package jsonchamp
+
+import (
+	"fmt"
+	goSlices "slices"
+	"strings"
+)
+
+const (
+	defaultDiffContextLines = 3
+	diffIndent              = "  "
+
+	ansiReset = "\x1b[0m"
+	ansiRed   = "\x1b[31m"
+	ansiGreen = "\x1b[32m"
+	ansiCyan  = "\x1b[36m"
+)
+
+type formatOptions struct {
+	color        bool
+	contextLines int
+}
+
+// FormatOption is a function that sets an option on a formatted diff.
+type FormatOption func(*formatOptions)
+
+// WithColor colors removed lines red, added lines green and hunk headers cyan using ANSI escape codes.
+func WithColor(color bool) FormatOption {
+	return func(o *formatOptions) {
+		o.color = color
+	}
+}
+
+// WithContextLines sets the number of unchanged lines shown around each change. The default is 3.
+func WithContextLines(n int) FormatOption {
+	return func(o *formatOptions) {
+		o.contextLines = max(n, 0)
+	}
+}
+
+// diffLine is a line of pretty-printed JSON and the path of the value it belongs to.
+type diffLine struct {
+	text string
+	path Path
+}
+
+// diffOp is a line in a line-oriented diff, prefixed with ' ', '-' or '+'.
+type diffOp struct {
+	prefix byte
+	line   diffLine
+}
+
+// FormatDiff renders the differences between two maps as a unified diff of their pretty-printed JSON,
+// with sorted keys. Each hunk header is annotated with the path of its first changed value, written by
+// Path.String, and the diff ends with a summary of the changes reported by Changes.
+// It returns an empty string if the maps are equal.
+func FormatDiff(a *Map, b *Map, opts ...FormatOption) string {
+	options := formatOptions{color: false, contextLines: defaultDiffContextLines}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	changes := Changes(a, b)
+	if len(changes) == 0 {
+		return ""
+	}
+
+	oldLines := prettyLines(nil, a, nil, "", "", 0)
+	newLines := prettyLines(nil, b, nil, "", "", 0)
+	ops := lineDiff(oldLines, newLines)
+
+	var sb strings.Builder
+
+	writeHunks(&sb, ops, options)
+	sb.WriteString(diffSummary(changes))
+	sb.WriteString("\n")
+
+	return sb.String()
+}
+
+// prettyLines appends the pretty-printed lines of a value. The prefix is written before the value,
+// typically the quoted key, and the suffix after it, typically a comma.
+func prettyLines(lines []diffLine, v any, path Path, prefix string, suffix string, depth int) []diffLine {
+	indent := strings.Repeat(diffIndent, depth)
+
+	switch t := v.(type) {
+	case *Map:
+		keys := t.Keys()
+		if len(keys) == 0 {
+			return append(lines, diffLine{text: indent + prefix + "{}" + suffix, path: path})
+		}
+
+		goSlices.Sort(keys)
+
+		lines = append(lines, diffLine{text: indent + prefix + "{", path: path})
+
+		for i, k := range keys {
+			child, _ := t.Get(k)
+			lines = prettyLines(lines, child, path.Child(k), string(marshalKey(k))+": ", comma(i, len(keys)), depth+1)
+		}
+
+		return append(lines, diffLine{text: indent + "}" + suffix, path: path})
+	case *List:
+		if t.Len() == 0 {
+			return append(lines, diffLine{text: indent + prefix + "[]" + suffix, path: path})
+		}
+
+		lines = append(lines, diffLine{text: indent + prefix + "[", path: path})
+
+		for i, child := range t.All() {
+			lines = prettyLines(lines, child, path.Index(i), "", comma(i, t.Len()), depth+1)
+		}
+
+		return append(lines, diffLine{text: indent + "]" + suffix, path: path})
+	default:
+		encoded, err := marshalValue(v)
+		if err != nil {
+			encoded = []byte(fmt.Sprint(v))
+		}
+
+		return append(lines, diffLine{text: indent + prefix + string(encoded) + suffix, path: path})
+	}
+}
+
+func comma(i int, n int) string {
+	if i < n-1 {
+		return ","
+	}
+
+	return ""
+}
+
+// lineDiff aligns two lists of lines and returns the unified sequence of kept, removed and added lines.
+func lineDiff(oldLines []diffLine, newLines []diffLine) []diffOp {
+	oldTexts := make([]any, len(oldLines))
+	for i, l := range oldLines {
+		oldTexts[i] = l.text
+	}
+
+	newTexts := make([]any, len(newLines))
+	for i, l := range newLines {
+		newTexts[i] = l.text
+	}
+
+	ops := make([]diffOp, 0, len(oldLines)+len(newLines))
+
+	// Removed lines are written before added lines within each run of changes.
+	var added []diffOp
+
+	for _, step := range alignSteps(anySlice(oldTexts), anySlice(newTexts)) {
+		switch {
+		case step.matched:
+			ops = append(ops, added...)
+			added = added[:0]
+			ops = append(ops, diffOp{prefix: ' ', line: newLines[step.newIndex]})
+		case step.kind == EditModify:
+			ops = append(ops, diffOp{prefix: '-', line: oldLines[step.oldIndex]})
+			added = append(added, diffOp{prefix: '+', line: newLines[step.newIndex]})
+		case step.kind == EditDelete:
+			ops = append(ops, diffOp{prefix: '-', line: oldLines[step.oldIndex]})
+		default:
+			added = append(added, diffOp{prefix: '+', line: newLines[step.newIndex]})
+		}
+	}
+
+	return append(ops, added...)
+}
+
+// writeHunks writes the changed lines of a diff in hunks with the configured number of context lines.
+func writeHunks(sb *strings.Builder, ops []diffOp, options formatOptions) {
+	context := options.contextLines
+
+	oldLine, newLine := 0, 0
+	i := 0
+
+	for i < len(ops) {
+		if ops[i].prefix == ' ' {
+			oldLine++
+			newLine++
+			i++
+
+			continue
+		}
+
+		// The leading context lines were counted as unchanged lines above.
+		start := max(i-context, 0)
+		oldLine -= i - start
+		newLine -= i - start
+
+		// Extend the hunk while the next change is close enough to share context lines.
+		lastChange := i
+		for k := i + 1; k < len(ops) && k-lastChange <= 2*context+1; k++ {
+			if ops[k].prefix != ' ' {
+				lastChange = k
+			}
+		}
+
+		end := min(lastChange+context+1, len(ops))
+		hunk := ops[start:end]
+
+		oldCount, newCount := 0, 0
+
+		for _, op := range hunk {
+			if op.prefix != '+' {
+				oldCount++
+			}
+
+			if op.prefix != '-' {
+				newCount++
+			}
+		}
+
+		header := fmt.Sprintf(
+			"@@ -%d,%d +%d,%d @@ %s",
+			hunkStart(oldLine, oldCount), oldCount, hunkStart(newLine, newCount), newCount, ops[i].line.path.String(),
+		)
+		writeDiffLine(sb, strings.TrimRight(header, " "), ansiCyan, options.color)
+
+		for _, op := range hunk {
+			switch op.prefix {
+			case '-':
+				writeDiffLine(sb, "-"+op.line.text, ansiRed, options.color)
+			case '+':
+				writeDiffLine(sb, "+"+op.line.text, ansiGreen, options.color)
+			default:
+				writeDiffLine(sb, " "+op.line.text, "", options.color)
+			}
+		}
+
+		oldLine += oldCount
+		newLine += newCount
+		i = end
+	}
+}
+
+// hunkStart returns the 1-based start line of a hunk, which is the line before it if the hunk is empty on that side.
+func hunkStart(linesBefore int, count int) int {
+	if count == 0 {
+		return linesBefore
+	}
+
+	return linesBefore + 1
+}
+
+func writeDiffLine(sb *strings.Builder, line string, color string, useColor bool) {
+	if useColor && color != "" {
+		sb.WriteString(color)
+		sb.WriteString(line)
+		sb.WriteString(ansiReset)
+	} else {
+		sb.WriteString(line)
+	}
+
+	sb.WriteString("\n")
+}
+
+func diffSummary(changes []Change) string {
+	counts := make(map[ChangeKind]int, 4)
+	for _, c := range changes {
+		counts[c.Kind]++
+	}
+
+	noun := "changes"
+	if len(changes) == 1 {
+		noun = "change"
+	}
+
+	return fmt.Sprintf(
+		"%d %s: %d added, %d removed, %d modified, %d type-changed",
+		len(changes), noun, counts[ChangeAdded], counts[ChangeRemoved], counts[ChangeModified], counts[ChangeTypeChanged],
+	)
+}
@@ -0,0 +1,102 @@
+package jsonchamp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatDiff(t *testing.T) {
+	t.Parallel()
+
+	a := mustParse(t, `{"name":"svc","db":{"host":"a","port":1}}`)
+	b := mustParse(t, `{"name":"svc","db":{"host":"b","port":1},"debug":true}`)
+
+	want := `@@ -1,7 +1,8 @@ db.host
+ {
+   "db": {
+-    "host": "a",
++    "host": "b",
+     "port": 1
+   },
++  "debug": true,
+   "name": "svc"
+ }
+2 changes: 1 added, 0 removed, 1 modified, 0 type-changed
+`
+
+	if got := FormatDiff(a, b); got != want {
+		t.Errorf("FormatDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatDiffContextLines(t *testing.T) {
+	t.Parallel()
+
+	a := mustParse(t, `{"a":1,"b":2,"c":3,"d":4,"e":5,"f":6,"g":7,"h":8}`)
+	b := mustParse(t, `{"a":0,"b":2,"c":3,"d":4,"e":5,"f":6,"g":7,"h":9}`)
+
+	want := `@@ -2,1 +2,1 @@ a
+-  "a": 1,
++  "a": 0,
+@@ -9,1 +9,1 @@ h
+-  "h": 8
++  "h": 9
+2 changes: 0 added, 0 removed, 2 modified, 0 type-changed
+`
+
+	if got := FormatDiff(a, b, WithContextLines(0)); got != want {
+		t.Errorf("FormatDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatDiffEscapesHunkPaths(t *testing.T) {
+	t.Parallel()
+
+	a := mustParse(t, `{"a.b":{"0":[1,2]}}`)
+	b := mustParse(t, `{"a.b":{"0":[1,3]}}`)
+
+	header, _, _ := strings.Cut(FormatDiff(a, b, WithContextLines(0)), "\n")
+	if want := `@@ -5,1 +5,1 @@ a\.b.\0.1`; header != want {
+		t.Errorf("header = %q, want %q", header, want)
+	}
+
+	if path := MustParsePath(`a\.b.\0.1`); !path.Equal(Path{KeySegment("a.b"), KeySegment("0"), IndexSegment(1)}) {
+		t.Errorf("the path in the header parses as %v", path)
+	}
+}
+
+func TestFormatDiffColor(t *testing.T) {
+	t.Parallel()
+
+	got := FormatDiff(NewFromItems("a", 1), NewFromItems("a", 2), WithColor(true))
+
+	if !strings.Contains(got, ansiRed+`-  "a": 1`+ansiReset) || !strings.Contains(got, ansiGreen+`+  "a": 2`+ansiReset) {
+		t.Errorf("expected colored lines, got %q", got)
+	}
+}
+
+func TestFormatDiffEqual(t *testing.T) {
+	t.Parallel()
+
+	if got := FormatDiff(NewFromItems("a", 1), NewFromItems("a", 1)); got != "" {
+		t.Errorf("expected no diff, got %q", got)
+	}
+}
+
+func TestFormatDiffLargeArray(t *testing.T) {
+	t.Parallel()
+
+	items := make([]any, 5000)
+	for i := range items {
+		items[i] = i
+	}
+
+	changed := append([]any{"first"}, items...)
+	changed[2501] = "changed"
+
+	got := FormatDiff(NewFromItems("items", items), NewFromItems("items", changed), WithContextLines(0))
+
+	if lines := strings.Count(got, "\n+") + strings.Count(got, "\n-"); lines != 3 {
+		t.Errorf("FormatDiff() has %d changed lines, want 3:\n%s", lines, got[:min(len(got), 500)])
+	}
+}
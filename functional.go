@@ -0,0 +1,216 @@
+package jsonchamp
+
+import (
+	"reflect"
+	goSlices "slices"
+)
+
+// Filter returns a map with the keys for which pred returns true.
+// If pred returns true for every key, the receiver is returned.
+func (m *Map) Filter(pred func(key string, value any) bool) *Map {
+	result := m
+
+	m.root.all(func(v *value) bool {
+		if !pred(v.key.key, v.value) {
+			result, _ = result.Delete(v.key.key)
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// FilterDeep is like Filter, but also filters the maps nested in the map and in its arrays.
+// pred is called with the path of every value, and nested maps are only filtered if pred keeps them.
+// Array elements are never removed, so the indices in the paths stay valid.
+func (m *Map) FilterDeep(pred func(path Path, value any) bool) *Map {
+	return filterDeep(nil, m, pred)
+}
+
+func filterDeep(path Path, m *Map, pred func(path Path, value any) bool) *Map {
+	result := m
+
+	m.root.all(func(v *value) bool {
+		valuePath := path.Child(v.key.key)
+
+		if !pred(valuePath, v.value) {
+			result, _ = result.Delete(v.key.key)
+
+			return true
+		}
+
+		if filtered := filterDeepValue(valuePath, v.value, pred); !sameInstance(filtered, v.value) {
+			result = result.Set(v.key.key, filtered)
+		}
+
+		return true
+	})
+
+	return result
+}
+
+func filterDeepValue(path Path, v any, pred func(path Path, value any) bool) any {
+	switch t := v.(type) {
+	case *Map:
+		return filterDeep(path, t, pred)
+	case *List:
+		return mapList(path, t, func(elementPath Path, element any) any {
+			return filterDeepValue(elementPath, element, pred)
+		})
+	default:
+		return v
+	}
+}
+
+// MapValues returns a map with every value replaced by the result of fn.
+// Keys for which fn returns the same value keep their nodes, and if no value changes the receiver is returned.
+func (m *Map) MapValues(fn func(key string, value any) any) *Map {
+	result := m
+
+	m.root.all(func(v *value) bool {
+		if mapped, changed := normalizeChanged(fn(v.key.key, v.value), v.value); changed {
+			result = result.Set(v.key.key, mapped)
+		}
+
+		return true
+	})
+
+	return result
+}
+
+// MapValuesDeep is like MapValues, but fn is called with the path of every value that is not a map or an array,
+// including the values nested in maps and arrays.
+func (m *Map) MapValuesDeep(fn func(path Path, value any) any) *Map {
+	return mapValuesDeep(nil, m, fn)
+}
+
+func mapValuesDeep(path Path, m *Map, fn func(path Path, value any) any) *Map {
+	return m.MapValues(func(key string, v any) any {
+		return mapValueDeep(path.Child(key), v, fn)
+	})
+}
+
+func mapValueDeep(path Path, v any, fn func(path Path, value any) any) any {
+	switch t := v.(type) {
+	case *Map:
+		return mapValuesDeep(path, t, fn)
+	case *List:
+		return mapList(path, t, func(elementPath Path, element any) any {
+			return mapValueDeep(elementPath, element, fn)
+		})
+	default:
+		return fn(path, v)
+	}
+}
+
+// mapList replaces the elements of a list with the results of fn, keeping the list if no element changes.
+func mapList(path Path, l *List, fn func(path Path, value any) any) *List {
+	result := l
+
+	for i, element := range l.All() {
+		elementPath := path.Index(i)
+		if mapped, changed := normalizeChanged(fn(elementPath, element), element); changed {
+			result = result.Set(i, mapped)
+		}
+	}
+
+	return result
+}
+
+// normalizeChanged normalizes the value returned by a callback, and returns false if it is the original value.
+// Normalizing copies native maps, so the value is compared with the original before it is normalized.
+func normalizeChanged(mapped any, original any) (any, bool) {
+	if sameInstance(mapped, original) {
+		return original, false
+	}
+
+	normalized := normalizeValue(mapped)
+
+	return normalized, !sameInstance(normalized, original)
+}
+
+// sameInstance returns true if two normalized values are the same value.
+// Maps, lists and native maps and slices are compared by identity, so nested structures are not compared
+// element by element, and other values must have the same type and value.
+func sameInstance(a any, b any) bool {
+	switch t := a.(type) {
+	case *Map:
+		other, ok := b.(*Map)
+
+		return ok && t == other
+	case *List:
+		other, ok := b.(*List)
+
+		return ok && t == other
+	default:
+		aType := reflect.TypeOf(a)
+		if aType == nil || aType.Comparable() {
+			return a == b
+		}
+
+		// Native maps and slices are stored as they are and cannot be compared with ==.
+		if aType != reflect.TypeOf(b) {
+			return false
+		}
+
+		aValue, bValue := reflect.ValueOf(a), reflect.ValueOf(b)
+
+		switch aType.Kind() {
+		case reflect.Map:
+			return aValue.UnsafePointer() == bValue.UnsafePointer()
+		case reflect.Slice:
+			return aValue.Len() == bValue.Len() && aValue.UnsafePointer() == bValue.UnsafePointer()
+		default:
+			return false
+		}
+	}
+}
+
+// Reduce folds the keys and values of a map into a single value, visiting the keys in sorted order.
+func Reduce[T any](m *Map, initial T, fn func(acc T, key string, value any) T) T {
+	acc := initial
+
+	keys := m.Keys()
+	goSlices.Sort(keys)
+
+	for _, key := range keys {
+		v, _ := m.Get(key)
+		acc = fn(acc, key, v)
+	}
+
+	return acc
+}
+
+// Pick returns a map with only the given keys. Keys that do not exist in the map are ignored.
+func (m *Map) Pick(keys ...string) *Map {
+	picked := &Map{root: newRootNode(), hasher: m.hasher, interner: m.interner}
+
+	for _, key := range keys {
+		if v, ok := m.root.lookup(newKey(key, m.hash(key))); ok {
+			picked.root = picked.root.setValue(v)
+		}
+	}
+
+	if picked.Len() == m.Len() {
+		return m
+	}
+
+	return picked
+}
+
+// Omit returns a map without the given keys.
+// If none of the keys exist in the map, the receiver is returned.
+func (m *Map) Omit(keys ...string) *Map {
+	result := m
+
+	for _, key := range keys {
+		result, _ = result.Delete(key)
+	}
+
+	if result.Len() == m.Len() {
+		return m
+	}
+
+	return result
+}
@@ -0,0 +1,175 @@
+package jsonchamp
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMapFilter(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":1,"_internal":2,"b":"x"}`)
+
+	got := m.Filter(func(key string, _ any) bool { return !strings.HasPrefix(key, "_") })
+	if want := mustParse(t, `{"a":1,"b":"x"}`); !got.Equals(want) {
+		t.Errorf("Filter() = %v, want %v", got.ToMap(), want.ToMap())
+	}
+
+	if got := m.Filter(func(string, any) bool { return true }); got != m {
+		t.Errorf("expected Filter to return the receiver when nothing is removed")
+	}
+}
+
+func TestMapFilterDeep(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":{"_id":1,"b":2},"items":[{"_id":3,"c":4},5],"unchanged":{"d":6}}`)
+
+	got := m.FilterDeep(func(path Path, _ any) bool {
+		return !strings.HasPrefix(path[len(path)-1].Key, "_")
+	})
+
+	want := mustParse(t, `{"a":{"b":2},"items":[{"c":4},5],"unchanged":{"d":6}}`)
+	if !got.Equals(want) {
+		t.Errorf("FilterDeep() = %v, want %v", got.ToMap(), want.ToMap())
+	}
+
+	before, _ := m.GetMap("unchanged")
+	after, _ := got.GetMap("unchanged")
+
+	if before != after {
+		t.Errorf("expected unchanged nested map to be shared")
+	}
+}
+
+func TestMapMapValues(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":"x","b":"y","c":1}`)
+
+	upper := func(_ string, v any) any {
+		if s, ok := v.(string); ok {
+			return strings.ToUpper(s)
+		}
+
+		return v
+	}
+
+	got := m.MapValues(upper)
+	if want := mustParse(t, `{"a":"X","b":"Y","c":1}`); !got.Equals(want) {
+		t.Errorf("MapValues() = %v, want %v", got.ToMap(), want.ToMap())
+	}
+
+	if same := got.MapValues(upper); same != got {
+		t.Errorf("expected MapValues to return the receiver when no value changes")
+	}
+}
+
+func TestMapMapValuesDeep(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":{"b":1,"c":[2,{"d":3}]},"e":"f"}`)
+
+	var paths []string
+
+	got := m.MapValuesDeep(func(path Path, v any) any {
+		paths = append(paths, path.String())
+
+		if n, ok := v.(int64); ok {
+			return n * 10
+		}
+
+		return v
+	})
+
+	if want := mustParse(t, `{"a":{"b":10,"c":[20,{"d":30}]},"e":"f"}`); !got.Equals(want) {
+		t.Errorf("MapValuesDeep() = %v, want %v", got.ToMap(), want.ToMap())
+	}
+
+	slices.Sort(paths)
+
+	if want := []string{"a.b", "a.c.0", "a.c.1.d", "e"}; !slices.Equal(paths, want) {
+		t.Errorf("visited paths = %v, want %v", paths, want)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"b":2,"a":1,"c":3}`)
+
+	keys := Reduce(m, "", func(acc string, key string, _ any) string { return acc + key })
+	if keys != "abc" {
+		t.Errorf("Reduce() = %q, want %q", keys, "abc")
+	}
+
+	sum := Reduce(m, int64(0), func(acc int64, _ string, v any) int64 { return acc + v.(int64) })
+	if sum != 6 {
+		t.Errorf("Reduce() = %d, want 6", sum)
+	}
+}
+
+func TestMapPickAndOmit(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":1,"b":2,"c":3}`)
+
+	tests := []struct {
+		name string
+		got  *Map
+		want string
+	}{
+		{name: "Pick", got: m.Pick("a", "c", "missing"), want: `{"a":1,"c":3}`},
+		{name: "Omit", got: m.Omit("a", "missing"), want: `{"b":2,"c":3}`},
+		{name: "Pick nothing", got: m.Pick(), want: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if want := mustParse(t, tt.want); !tt.got.Equals(want) {
+				t.Errorf("%s() = %v, want %v", tt.name, tt.got.ToMap(), want.ToMap())
+			}
+		})
+	}
+
+	if got := m.Pick("a", "b", "c"); got != m {
+		t.Errorf("expected Pick of all keys to return the receiver")
+	}
+
+	if got := m.Omit("missing"); got != m {
+		t.Errorf("expected Omit of missing keys to return the receiver")
+	}
+}
+
+func TestFunctionalWithNativeValues(t *testing.T) {
+	t.Parallel()
+
+	m := New().Set("a", map[string]any{"x": 1}).Set("b", []string{"y"})
+	identity := func(_ string, v any) any { return v }
+
+	if got := m.MapValues(identity); got != m {
+		t.Errorf("expected MapValues to return the receiver when no value changes")
+	}
+
+	if got := m.FilterDeep(func(Path, any) bool { return true }); got != m {
+		t.Errorf("expected FilterDeep to return the receiver when nothing is removed")
+	}
+
+	if got := Transform(m, func(Path, any) (any, TransformAction) { return nil, TransformKeep }); got != m {
+		t.Errorf("expected Transform to return the receiver when nothing changes")
+	}
+
+	replaced := m.MapValues(func(key string, v any) any {
+		if key == "a" {
+			return map[string]any{"x": 1}
+		}
+
+		return v
+	})
+	if replaced == m || !replaced.Equals(m) {
+		t.Errorf("expected MapValues to return an equal copy when a native map is replaced")
+	}
+}
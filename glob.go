@@ -0,0 +1,245 @@
+package jsonchamp
+
+import (
+	"fmt"
+	goSlices "slices"
+)
+
+const (
+	globOne = "*"
+	globAny = "**"
+)
+
+// Match is a value found by GetAll and its concrete path.
+type Match struct {
+	Path  Path
+	Value any
+}
+
+// GetAll returns the values matching the pattern, ordered by path.
+// Patterns use the syntax of ParsePath, where the segment * matches any key or index on one level
+// and the segment ** matches any number of levels, including none. For example, users.*.password
+// matches the password of every user, and **.timeout matches every timeout in the map.
+func (m *Map) GetAll(pattern string) ([]Match, error) {
+	var matches []Match
+
+	_, err := m.applyGlob(pattern, func(path Path, v any) (any, TransformAction) {
+		matches = append(matches, Match{Path: path, Value: v})
+
+		return nil, TransformKeep
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	goSlices.SortFunc(matches, func(a Match, b Match) int { return a.Path.Compare(b.Path) })
+
+	return matches, nil
+}
+
+// SetAll returns a new map with every value matching the pattern replaced by the value.
+// It does not create values that do not exist. See GetAll for the pattern syntax.
+func (m *Map) SetAll(pattern string, value any) (*Map, error) {
+	value = normalizeValue(value)
+
+	return m.applyGlob(pattern, func(Path, any) (any, TransformAction) {
+		return value, TransformReplace
+	})
+}
+
+// DeleteAll returns a new map without the values matching the pattern.
+// Deleted array elements are removed from their arrays. See GetAll for the pattern syntax.
+func (m *Map) DeleteAll(pattern string) (*Map, error) {
+	return m.applyGlob(pattern, func(Path, any) (any, TransformAction) {
+		return nil, TransformDelete
+	})
+}
+
+// UpdateAll returns a new map with every value matching the pattern replaced by the result of fn.
+// See GetAll for the pattern syntax.
+func (m *Map) UpdateAll(pattern string, fn func(path Path, v any) any) (*Map, error) {
+	return m.applyGlob(pattern, func(path Path, v any) (any, TransformAction) {
+		return fn(path, v), TransformReplace
+	})
+}
+
+// applyGlob applies fn to the values matching the pattern. Only the maps and arrays on the way to a match
+// are rebuilt, and if nothing changes the receiver is returned.
+func (m *Map) applyGlob(pattern string, fn func(path Path, v any) (any, TransformAction)) (*Map, error) {
+	glob, err := ParsePath(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(glob) == 0 {
+		return nil, fmt.Errorf("%w: empty pattern", ErrInvalidPath)
+	}
+
+	result, _ := globValue(nil, m, glob, fn)
+
+	resultMap, ok := result.(*Map)
+	if !ok {
+		panic(fmt.Sprintf("expected *Map, got %T", result))
+	}
+
+	return resultMap, nil
+}
+
+func isGlob(segment PathSegment, glob string) bool {
+	return !segment.IsIndex && segment.Key == glob
+}
+
+// matchGlob reports whether the path matches the pattern.
+func matchGlob(pattern Path, path Path) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	segment, rest := pattern[0], pattern[1:]
+
+	if isGlob(segment, globAny) {
+		for i := range len(path) + 1 {
+			if matchGlob(rest, path[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(path) == 0 || (!isGlob(segment, globOne) && segment != path[0]) {
+		return false
+	}
+
+	return matchGlob(rest, path[1:])
+}
+
+// globValue applies fn to the values under v matching the pattern, and returns the new value of v
+// and false if v was deleted.
+func globValue(path Path, v any, pattern Path, fn func(path Path, v any) (any, TransformAction)) (any, bool) {
+	if len(pattern) == 0 {
+		// The root map itself is never matched.
+		if len(path) == 0 {
+			return v, true
+		}
+
+		replacement, action := fn(path, v)
+
+		switch action {
+		case TransformDelete:
+			return nil, false
+		case TransformReplace:
+			return normalizeValue(replacement), true
+		case TransformKeep, TransformSkip:
+		}
+
+		return v, true
+	}
+
+	segment, rest := pattern[0], pattern[1:]
+
+	if !isGlob(segment, globAny) {
+		return globChildren(path, v, segment, rest, fn), true
+	}
+
+	// The children are matched against the whole pattern before the value is matched against the rest of it,
+	// so values returned by fn are never matched again.
+	v = globChildren(path, v, KeySegment(globOne), pattern, fn)
+
+	return globValue(path, v, rest, fn)
+}
+
+// globChildren applies globValue to the children of v selected by the segment.
+func globChildren(
+	path Path,
+	v any,
+	segment PathSegment,
+	rest Path,
+	fn func(path Path, v any) (any, TransformAction),
+) any {
+	switch t := v.(type) {
+	case *Map:
+		result := t
+
+		keys := []string{segment.String()}
+		if isGlob(segment, globOne) {
+			keys = goSlices.Sorted(goSlices.Values(t.Keys()))
+		}
+
+		for _, key := range keys {
+			child, ok := t.Get(key)
+			if !ok {
+				continue
+			}
+
+			updated, keep := globValue(path.Child(key), child, rest, fn)
+
+			switch {
+			case !keep:
+				result, _ = result.Delete(key)
+			case !sameInstance(updated, child):
+				result = result.Set(key, updated)
+			}
+		}
+
+		return result
+	case *List:
+		return globList(path, t, segment, rest, fn)
+	default:
+		return v
+	}
+}
+
+func globList(
+	path Path,
+	l *List,
+	segment PathSegment,
+	rest Path,
+	fn func(path Path, v any) (any, TransformAction),
+) *List {
+	selected := func(int) bool { return true }
+
+	if !isGlob(segment, globOne) {
+		index, ok := segment.Index, segment.IsIndex
+		if !ok {
+			index, ok = parseIndex(segment.Key)
+		}
+
+		if !ok || index >= l.Len() {
+			return l
+		}
+
+		selected = func(i int) bool { return i == index }
+	}
+
+	result := l
+	kept := make([]any, 0, l.Len())
+	deleted := false
+
+	for i, element := range l.All() {
+		if !selected(i) {
+			kept = append(kept, element)
+
+			continue
+		}
+
+		updated, keep := globValue(path.Index(i), element, rest, fn)
+		if !keep {
+			deleted = true
+
+			continue
+		}
+
+		kept = append(kept, updated)
+
+		if !sameInstance(updated, element) {
+			result = result.Set(i, updated)
+		}
+	}
+
+	if deleted {
+		return newListFromValues(kept)
+	}
+
+	return result
+}
@@ -0,0 +1,166 @@
+package jsonchamp
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestGetAll(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{
+		"users": [{"name":"a","password":"x"},{"name":"b","password":"y"}],
+		"services": {"api": {"timeout": 1, "db": {"timeout": 2}}, "timeout": 3}
+	}`)
+
+	tests := []struct {
+		pattern string
+		paths   []string
+		values  []any
+	}{
+		{pattern: "users.*.password", paths: []string{"users.0.password", "users.1.password"}, values: []any{"x", "y"}},
+		{pattern: "users[1].name", paths: []string{"users.1.name"}, values: []any{"b"}},
+		{
+			pattern: "services.**.timeout",
+			paths:   []string{"services.api.db.timeout", "services.api.timeout", "services.timeout"},
+			values:  []any{int64(2), int64(1), int64(3)},
+		},
+		{pattern: "**.db", paths: []string{"services.api.db"}, values: []any{mustParse(t, `{"timeout":2}`)}},
+		{pattern: "missing.*", paths: nil, values: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			t.Parallel()
+
+			matches, err := m.GetAll(tt.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var paths []string
+
+			var values []any
+
+			for _, match := range matches {
+				paths = append(paths, match.Path.String())
+				values = append(values, match.Value)
+			}
+
+			if !slices.Equal(paths, tt.paths) || !equalsAnyList(values, tt.values) {
+				t.Errorf("GetAll(%s) = %v %v, want %v %v", tt.pattern, paths, values, tt.paths, tt.values)
+			}
+		})
+	}
+}
+
+func TestSetDeleteUpdateAll(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{
+		"users": [{"name":"a","password":"x"},{"name":"b"}],
+		"services": {"api": {"timeout": 1}, "web": {"timeout": 2}},
+		"other": {"n": 1}
+	}`)
+
+	redacted, err := m.SetAll("users.*.password", "***")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := mustParse(t, `{
+		"users": [{"name":"a","password":"***"},{"name":"b"}],
+		"services": {"api": {"timeout": 1}, "web": {"timeout": 2}},
+		"other": {"n": 1}
+	}`); !redacted.Equals(want) {
+		t.Errorf("SetAll() = %v, want %v", redacted.ToMap(), want.ToMap())
+	}
+
+	bumped, err := m.UpdateAll("services.**.timeout", func(_ Path, v any) any { return v.(int64) * 10 })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := bumped.Get(MustParsePath("services.web.timeout")); got != int64(20) {
+		t.Errorf("UpdateAll() timeout = %v, want 20", got)
+	}
+
+	before, _ := m.GetMap("other")
+	after, _ := bumped.GetMap("other")
+
+	if before != after {
+		t.Errorf("expected unaffected branch to be shared")
+	}
+
+	deleted, err := m.DeleteAll("users.*.password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if matches, _ := deleted.GetAll("**.password"); len(matches) != 0 {
+		t.Errorf("expected no passwords after DeleteAll, got %v", matches)
+	}
+
+	withoutFirst, err := m.DeleteAll("users.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if users, _ := withoutFirst.GetList("users"); users.Len() != 1 {
+		t.Errorf("expected one user after DeleteAll, got %d", users.Len())
+	}
+
+	if unchanged, _ := m.SetAll("missing.*", 1); unchanged != m {
+		t.Errorf("expected SetAll without matches to return the receiver")
+	}
+}
+
+func TestGlobNeverMatchesRoot(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":{"b":1}}`)
+
+	wrapped, err := m.UpdateAll("**", func(_ Path, v any) any { return NewFromItems("wrapped", v) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := mustParse(t, `{"a":{"wrapped":{"b":{"wrapped":1}}}}`); !wrapped.Equals(want) {
+		t.Errorf("UpdateAll(**) = %v, want %v", wrapped.ToMap(), want.ToMap())
+	}
+
+	if _, err := m.GetAll(""); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("GetAll(\"\") error = %v, want %v", err, ErrInvalidPath)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{pattern: "items", path: "items", want: true},
+		{pattern: "items", path: "other", want: false},
+		{pattern: "orders.*.lines", path: "orders.3.lines", want: true},
+		{pattern: "orders.*.lines", path: "orders.lines", want: false},
+		{pattern: "orders.0.lines", path: "orders.1.lines", want: false},
+		{pattern: "**.tags", path: "tags", want: true},
+		{pattern: "**.tags", path: "a.0.b.tags", want: true},
+		{pattern: "**.tags", path: "a.tags.b", want: false},
+		{pattern: "a.**", path: "a", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+" "+tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			if got := matchGlob(MustParsePath(tt.pattern), MustParsePath(tt.path)); got != tt.want {
+				t.Errorf("matchGlob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package jsonchamp
+
+import (
+	"fmt"
+	goSlices "slices"
+	"time"
+)
+
+// Version is a committed version of a map in a History.
+type Version struct {
+	// Number identifies the version. Numbers increase with every commit, starting at 1 for the initial map.
+	Number  int
+	Map     *Map
+	Time    time.Time
+	Author  string
+	Message string
+}
+
+type historyOptions struct {
+	clock       func() time.Time
+	maxVersions int
+	maxAge      time.Duration
+}
+
+// HistoryOption is a function that sets an option on a history.
+type HistoryOption func(*historyOptions)
+
+// WithClock sets the function used to timestamp versions. The default is time.Now.
+func WithClock(clock func() time.Time) HistoryOption {
+	return func(o *historyOptions) {
+		o.clock = clock
+	}
+}
+
+// WithMaxVersions prunes the oldest versions after each commit so at most n versions are kept.
+func WithMaxVersions(n int) HistoryOption {
+	return func(o *historyOptions) {
+		o.maxVersions = n
+	}
+}
+
+// WithMaxAge prunes versions older than the age after each commit.
+func WithMaxAge(age time.Duration) HistoryOption {
+	return func(o *historyOptions) {
+		o.maxAge = age
+	}
+}
+
+// History records committed versions of a map and moves between them with Undo and Redo.
+// Versions share all unchanged nodes of the map, so keeping many versions of a large map is cheap.
+// A History is not safe for concurrent use.
+type History struct {
+	options  historyOptions
+	versions []Version
+	// current is the index of the current version in versions. Versions after it can be redone.
+	current int
+}
+
+// NewHistory creates a history with the initial map as version 1.
+func NewHistory(initial *Map, opts ...HistoryOption) *History {
+	options := historyOptions{clock: time.Now, maxVersions: 0, maxAge: 0}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	h := &History{options: options, versions: nil, current: 0}
+	h.versions = append(h.versions, h.newVersion(1, initial, "", ""))
+
+	return h
+}
+
+func (h *History) newVersion(number int, m *Map, author string, message string) Version {
+	return Version{Number: number, Map: m, Time: h.options.clock(), Author: author, Message: message}
+}
+
+// Commit records a new version after the current one and makes it current.
+// Versions that were undone are discarded and can no longer be redone.
+func (h *History) Commit(m *Map, author string, message string) Version {
+	number := h.versions[len(h.versions)-1].Number + 1
+	h.versions = append(h.versions[:h.current+1], h.newVersion(number, m, author, message))
+	h.current = len(h.versions) - 1
+
+	if h.options.maxVersions > 0 {
+		h.PruneCount(h.options.maxVersions)
+	}
+
+	if h.options.maxAge > 0 {
+		h.PruneAge(h.options.maxAge)
+	}
+
+	return h.versions[h.current]
+}
+
+// Current returns the current version.
+func (h *History) Current() Version {
+	return h.versions[h.current]
+}
+
+// Undo makes the version before the current one current. It returns false if there is no earlier version.
+func (h *History) Undo() (Version, bool) {
+	if h.current == 0 {
+		return h.versions[h.current], false
+	}
+
+	h.current--
+
+	return h.versions[h.current], true
+}
+
+// Redo makes the version that was last undone current again. It returns false if there is nothing to redo.
+func (h *History) Redo() (Version, bool) {
+	if h.current == len(h.versions)-1 {
+		return h.versions[h.current], false
+	}
+
+	h.current++
+
+	return h.versions[h.current], true
+}
+
+// At returns the version with the number, if it has not been pruned or discarded.
+func (h *History) At(number int) (Version, error) {
+	i, found := goSlices.BinarySearchFunc(h.versions, number, func(v Version, n int) int { return v.Number - n })
+	if !found {
+		var zero Version
+
+		return zero, fmt.Errorf("%w: %d", ErrVersionNotFound, number)
+	}
+
+	return h.versions[i], nil
+}
+
+// Log returns the kept versions, oldest first, including the versions that can be redone.
+func (h *History) Log() []Version {
+	return goSlices.Clone(h.versions)
+}
+
+// Diff returns the changes that turn the map of one version into the map of another.
+func (h *History) Diff(from int, to int, opts ...DiffOption) ([]Change, error) {
+	fromVersion, err := h.At(from)
+	if err != nil {
+		return nil, err
+	}
+
+	toVersion, err := h.At(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return Changes(fromVersion.Map, toVersion.Map, opts...), nil
+}
+
+// PruneCount removes the oldest versions so at most n versions are kept, and returns the number removed.
+// The current version is never removed.
+func (h *History) PruneCount(n int) int {
+	return h.prune(func(i int) bool { return len(h.versions)-i > n })
+}
+
+// PruneAge removes the versions older than the age, and returns the number removed.
+// The current version is never removed.
+func (h *History) PruneAge(age time.Duration) int {
+	cutoff := h.options.clock().Add(-age)
+
+	return h.prune(func(i int) bool { return h.versions[i].Time.Before(cutoff) })
+}
+
+// prune removes the versions from the oldest one for as long as remove returns true for their index.
+func (h *History) prune(remove func(i int) bool) int {
+	removed := 0
+	for removed < h.current && remove(removed) {
+		removed++
+	}
+
+	h.versions = goSlices.Delete(h.versions, 0, removed)
+	h.current -= removed
+
+	return removed
+}
@@ -0,0 +1,125 @@
+package jsonchamp
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// testClock returns a clock that advances a minute every time it is read.
+func testClock() func() time.Time {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return func() time.Time {
+		now = now.Add(time.Minute)
+
+		return now
+	}
+}
+
+func TestHistoryUndoRedo(t *testing.T) {
+	t.Parallel()
+
+	h := NewHistory(NewFromItems("a", 1), WithClock(testClock()))
+	h.Commit(h.Current().Map.Set("a", 2), "alice", "set a to 2")
+	h.Commit(h.Current().Map.Set("a", 3), "bob", "set a to 3")
+
+	if v := h.Current(); v.Number != 3 || v.Author != "bob" || v.Message != "set a to 3" {
+		t.Errorf("Current() = %+v, want version 3 by bob", v)
+	}
+
+	undone, ok := h.Undo()
+	if a, _ := undone.Map.GetInt("a"); !ok || undone.Number != 2 || a != 2 {
+		t.Errorf("Undo() = %d, %v, want version 2", undone.Number, ok)
+	}
+
+	h.Undo()
+
+	if _, ok := h.Undo(); ok {
+		t.Errorf("expected Undo at the first version to return false")
+	}
+
+	if redone, ok := h.Redo(); !ok || redone.Number != 2 {
+		t.Errorf("Redo() = %d, %v, want version 2", redone.Number, ok)
+	}
+
+	// Committing after an undo discards the versions that could be redone.
+	committed := h.Commit(h.Current().Map.Set("b", true), "carol", "add b")
+	if committed.Number != 4 {
+		t.Errorf("Commit() number = %d, want 4", committed.Number)
+	}
+
+	if _, ok := h.Redo(); ok {
+		t.Errorf("expected nothing to redo after a commit")
+	}
+
+	var numbers []int
+	for _, v := range h.Log() {
+		numbers = append(numbers, v.Number)
+	}
+
+	if len(numbers) != 3 || numbers[0] != 1 || numbers[1] != 2 || numbers[2] != 4 {
+		t.Errorf("Log() numbers = %v, want [1 2 4]", numbers)
+	}
+
+	if _, err := h.At(3); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("At(3) error = %v, want %v", err, ErrVersionNotFound)
+	}
+}
+
+func TestHistoryDiff(t *testing.T) {
+	t.Parallel()
+
+	h := NewHistory(NewFromItems("a", 1))
+	h.Commit(h.Current().Map.Set("a", 2), "", "")
+	h.Commit(h.Current().Map.Set("b", "x"), "", "")
+
+	changes, err := h.Diff(1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Change{
+		{Path: MustParsePath("a"), Kind: ChangeModified, Old: int64(1), New: int64(2)},
+		{Path: MustParsePath("b"), Kind: ChangeAdded, Old: nil, New: "x"},
+	}
+
+	if len(changes) != len(want) || !equalChange(changes[0], want[0]) || !equalChange(changes[1], want[1]) {
+		t.Errorf("Diff() = %v, want %v", changes, want)
+	}
+
+	if _, err := h.Diff(1, 9); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("Diff() error = %v, want %v", err, ErrVersionNotFound)
+	}
+}
+
+func TestHistoryPrune(t *testing.T) {
+	t.Parallel()
+
+	h := NewHistory(New(), WithClock(testClock()), WithMaxVersions(3))
+	for i := range 5 {
+		h.Commit(h.Current().Map.Set("i", i), "", "")
+	}
+
+	if log := h.Log(); len(log) != 3 || log[0].Number != 4 {
+		t.Errorf("expected versions 4 to 6 to be kept, got %d versions from %d", len(log), log[0].Number)
+	}
+
+	h.Undo()
+	h.Undo()
+
+	// The current version is never pruned.
+	if removed := h.PruneCount(1); removed != 0 || h.Current().Number != 4 {
+		t.Errorf("PruneCount() removed %d versions, current is %d", removed, h.Current().Number)
+	}
+
+	aged := NewHistory(New(), WithClock(testClock()))
+	for i := range 5 {
+		aged.Commit(aged.Current().Map.Set("i", i), "", "")
+	}
+
+	// The clock is at 7 minutes and versions were made at minutes 1 to 6.
+	if removed := aged.PruneAge(3 * time.Minute); removed != 3 {
+		t.Errorf("PruneAge() removed %d versions, want 3", removed)
+	}
+}
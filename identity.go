@@ -0,0 +1,73 @@
+package jsonchamp
+
+import (
+	"fmt"
+)
+
+// IdentityKey declares the field that identifies the records in the arrays at a path.
+type IdentityKey struct {
+	// Path is the pattern of the paths of the arrays, with the globs of GetAll: the segment * matches
+	// any key or index on one level and ** matches any number of levels.
+	Path Path
+	// Field is the key in each record whose value identifies the record.
+	Field string
+}
+
+// IdentityKeys is a list of identity keys for different arrays.
+type IdentityKeys []IdentityKey
+
+// ParseIdentityKeys parses identity keys written as patterns in the syntax of GetAll, where the * before the
+// last key matches the records of the array and the last key is the identity field.
+// For example, "items.*.id" identifies the records in the "items" array by their "id" field and
+// "orders.*.lines.*.sku" identifies the lines of every order by their "sku" field.
+func ParseIdentityKeys(specs ...string) (IdentityKeys, error) {
+	keys := make(IdentityKeys, 0, len(specs))
+
+	for _, spec := range specs {
+		pattern, err := ParsePath(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		n := len(pattern)
+		if n < 3 || !isGlob(pattern[n-2], globOne) || pattern[n-1].IsIndex ||
+			isGlob(pattern[n-1], globOne) || isGlob(pattern[n-1], globAny) {
+			return nil, fmt.Errorf("%w: identity key '%s' must have the form 'array.*.field'", ErrInvalidPath, spec)
+		}
+
+		keys = append(keys, IdentityKey{Path: pattern[:n-2], Field: pattern[n-1].Key})
+	}
+
+	return keys, nil
+}
+
+// fieldFor returns the identity field for the array at the path.
+func (keys IdentityKeys) fieldFor(path Path) (string, bool) {
+	for _, k := range keys {
+		if matchGlob(k.Path, path) {
+			return k.Field, true
+		}
+	}
+
+	return "", false
+}
+
+// recordIdentity returns a comparable identity for a record, or false if the record has no identity field.
+func recordIdentity(record any, field string) (string, bool) {
+	recordMap, ok := record.(*Map)
+	if !ok {
+		return "", false
+	}
+
+	id, ok := recordMap.Get(field)
+	if !ok {
+		return "", false
+	}
+
+	encoded, err := marshalValue(id)
+	if err != nil {
+		return "", false
+	}
+
+	return string(encoded), true
+}
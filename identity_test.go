@@ -0,0 +1,96 @@
+package jsonchamp
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseIdentityKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec    string
+		want    IdentityKey
+		wantErr bool
+	}{
+		{spec: "items.*.id", want: IdentityKey{Path: MustParsePath("items"), Field: "id"}},
+		{spec: "spec.items.*.name", want: IdentityKey{Path: MustParsePath("spec.items"), Field: "name"}},
+		{spec: "orders.*.lines.*.sku", want: IdentityKey{Path: MustParsePath("orders.*.lines"), Field: "sku"}},
+		{spec: "**.tags.*.name", want: IdentityKey{Path: MustParsePath("**.tags"), Field: "name"}},
+		{spec: "items.id", wantErr: true},
+		{spec: "*.id", wantErr: true},
+		{spec: "items.*.meta.id", wantErr: true},
+		{spec: "items.*.*", wantErr: true},
+		{spec: "items.*.0", wantErr: true},
+		{spec: "items[.*.id", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			t.Parallel()
+
+			keys, err := ParseIdentityKeys(tt.spec)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPath) {
+					t.Fatalf("expected ErrInvalidPath, got %v", err)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseIdentityKeys() error = %v", err)
+			}
+
+			if !keys[0].Path.Equal(tt.want.Path) || keys[0].Field != tt.want.Field {
+				t.Errorf("ParseIdentityKeys() = %+v, want %+v", keys[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestChangesWithIdentityKeys(t *testing.T) {
+	t.Parallel()
+
+	keys, err := ParseIdentityKeys("items.*.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := mustParse(t, `{"items":[{"id":1,"name":"a"},{"id":2,"name":"b"},{"id":3,"name":"c"}]}`)
+	b := mustParse(t, `{"items":[{"id":3,"name":"c"},{"id":2,"name":"x"},{"id":4,"name":"d"}]}`)
+
+	got := Changes(a, b, WithDiffIdentityKeys(keys))
+	want := []Change{
+		{Path: MustParsePath("items.0"), Kind: ChangeRemoved, Old: mustParse(t, `{"id":1,"name":"a"}`)},
+		{Path: MustParsePath("items.1.name"), Kind: ChangeModified, Old: "b", New: "x"},
+		{Path: MustParsePath("items.2"), Kind: ChangeAdded, New: mustParse(t, `{"id":4,"name":"d"}`)},
+	}
+
+	if !slices.EqualFunc(got, want, equalChange) {
+		t.Errorf("Changes() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMergeWithIdentityKeys(t *testing.T) {
+	t.Parallel()
+
+	keys, err := ParseIdentityKeys("orders.*.lines.*.sku", "orders.*.id")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := mustParse(t, `{"orders":[{"id":1,"lines":[{"sku":"a","qty":1},{"sku":"b","qty":1}]}]}`)
+	other := mustParse(t, `{"orders":[{"id":1,"lines":[{"sku":"b","qty":2}]},{"id":2,"lines":[]}]}`)
+
+	got, _, err := current.MergeWith(other, WithMergeIdentityKeys(keys))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := mustParse(t, `{"orders":[{"id":1,"lines":[{"sku":"a","qty":1},{"sku":"b","qty":2}]},{"id":2,"lines":[]}]}`)
+	if !got.Equals(want) {
+		t.Errorf("MergeWith() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,296 @@
+package jsonchamp
+
+import (
+	"crypto/sha256"
+	"maps"
+	"math"
+	"reflect"
+	goSlices "slices"
+	"sync"
+	"unsafe"
+)
+
+// Interner deduplicates strings, maps and arrays, so that equal values set in different maps are stored once.
+// Maps and arrays are looked up by their ContentHash and compared with the values held under the same digest,
+// and their keys and values are interned before they are stored, so equal sub trees are shared at every level.
+// Since maps are immutable, sharing a value between documents is safe.
+//
+// Maps created with WithInterner intern the keys and values passed to Set and decoded by UnmarshalJSON.
+// Values held by the interner are kept in memory for as long as the interner is. It is safe for concurrent use.
+type Interner struct {
+	mu      sync.Mutex
+	strings map[string]string
+	values  map[internKey][]any
+	stats   InternerStats
+}
+
+// internKey identifies the interned maps or arrays with the same digest. Maps with different hashers are
+// interned separately, so that interning does not change the hasher of a value.
+type internKey struct {
+	sum    [sha256.Size]byte
+	hasher *keyHasher
+}
+
+// InternerStats describes the values held by an Interner and the memory it saved.
+type InternerStats struct {
+	// Strings, Maps and Arrays are the numbers of distinct values held by the interner.
+	Strings int
+	Maps    int
+	Arrays  int
+	// Hits is the number of values that were replaced by an equal value held by the interner.
+	Hits int
+	// BytesSaved is an estimate of the memory used by the values that were replaced.
+	BytesSaved int64
+}
+
+// NewInterner creates an empty interner.
+func NewInterner() *Interner {
+	return &Interner{
+		mu:      sync.Mutex{},
+		strings: make(map[string]string),
+		values:  make(map[internKey][]any),
+		stats:   InternerStats{Strings: 0, Maps: 0, Arrays: 0, Hits: 0, BytesSaved: 0},
+	}
+}
+
+// Intern returns the value held by the interner that is equal to v, or adds v to the interner.
+// Values other than strings, maps and arrays are returned as they are.
+func (in *Interner) Intern(v any) any {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return in.intern(normalizeValue(v))
+}
+
+// Stats returns the statistics of the interner.
+func (in *Interner) Stats() InternerStats {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return in.stats
+}
+
+// internEntry interns the key and the normalized value of a map entry. A nil interner returns them as they are.
+func (in *Interner) internEntry(key string, v any) (string, any) {
+	if in == nil {
+		return key, v
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+
+	return in.internString(key), in.intern(v)
+}
+
+func (in *Interner) intern(v any) any {
+	switch t := v.(type) {
+	case string:
+		return in.internString(t)
+	case *Map:
+		return in.internMap(t)
+	case *List:
+		return in.internList(t)
+	default:
+		return v
+	}
+}
+
+func (in *Interner) internString(s string) string {
+	existing, ok := in.strings[s]
+	if !ok {
+		in.strings[s] = s
+		in.stats.Strings++
+
+		return s
+	}
+
+	if unsafe.StringData(existing) != unsafe.StringData(s) {
+		in.hit(estimatedSize(s))
+	}
+
+	return existing
+}
+
+func (in *Interner) internMap(m *Map) *Map {
+	k := internKey{sum: m.ContentHash(), hasher: m.hasher}
+
+	for _, candidate := range in.values[k] {
+		if existing, ok := candidate.(*Map); ok && identical(existing, m) {
+			if existing.root != m.root {
+				in.hit(estimatedSize(m))
+			}
+
+			return existing
+		}
+	}
+
+	root := m.root
+
+	m.root.all(func(v *value) bool {
+		key := in.internString(v.key.key)
+		interned := in.intern(v.value)
+
+		if unsafe.StringData(key) != unsafe.StringData(v.key.key) || !sameInstance(interned, v.value) {
+			root = root.setValue(&value{key: newKey(key, v.key.hash), value: interned})
+		}
+
+		return true
+	})
+
+	// Maps from the interner keep interning the values set in them.
+	canonical := &Map{root: root, hasher: m.hasher, interner: in}
+	in.values[k] = append(in.values[k], canonical)
+	in.stats.Maps++
+
+	return canonical
+}
+
+func (in *Interner) internList(l *List) *List {
+	k := internKey{sum: l.contentDigest().sum, hasher: nil}
+
+	for _, candidate := range in.values[k] {
+		if existing, ok := candidate.(*List); ok && identical(existing, l) {
+			if existing != l {
+				in.hit(estimatedSize(l))
+			}
+
+			return existing
+		}
+	}
+
+	values := l.Values()
+	changed := false
+
+	for i, v := range values {
+		interned := in.intern(v)
+		if !sameInstance(interned, v) {
+			values[i] = interned
+			changed = true
+		}
+	}
+
+	canonical := l
+	if changed {
+		canonical = newListFromValues(values)
+	}
+
+	in.values[k] = append(in.values[k], canonical)
+	in.stats.Arrays++
+
+	return canonical
+}
+
+// identical compares two values exactly. Unlike equalsAny, floats must have the same bits, since an interned
+// value replaces the original.
+func identical(a any, b any) bool {
+	switch t := a.(type) {
+	case *Map:
+		other, ok := b.(*Map)
+		if !ok || t.Len() != other.Len() {
+			return false
+		}
+
+		if t.root == other.root {
+			return true
+		}
+
+		return t.root.all(func(v *value) bool {
+			otherValue, exists := other.Get(v.key.key)
+
+			return exists && identical(v.value, otherValue)
+		})
+	case *List:
+		other, ok := b.(*List)
+		if !ok || t.Len() != other.Len() {
+			return false
+		}
+
+		if t == other {
+			return true
+		}
+
+		for i, v := range t.All() {
+			if !identical(v, other.at(i)) {
+				return false
+			}
+		}
+
+		return true
+	case []any:
+		other, ok := b.([]any)
+
+		return ok && goSlices.EqualFunc(t, other, identical)
+	case map[string]any:
+		other, ok := b.(map[string]any)
+
+		return ok && maps.EqualFunc(t, other, identical)
+	case float64:
+		other, ok := b.(float64)
+
+		return ok && math.Float64bits(t) == math.Float64bits(other)
+	default:
+		return reflect.DeepEqual(a, b)
+	}
+}
+
+func (in *Interner) hit(size int64) {
+	in.stats.Hits++
+	in.stats.BytesSaved += size
+}
+
+// estimatedSize estimates the memory used by a value, including the trie nodes of maps and arrays.
+func estimatedSize(v any) int64 {
+	const interfaceSize = int64(unsafe.Sizeof(any(nil)))
+
+	switch t := v.(type) {
+	case string:
+		return int64(unsafe.Sizeof(t)) + int64(len(t))
+	case *Map:
+		return int64(unsafe.Sizeof(*t)) + t.root.estimatedSize()
+	case *List:
+		size := int64(unsafe.Sizeof(*t))
+		for _, element := range t.All() {
+			size += interfaceSize + estimatedSize(element)
+		}
+
+		return size
+	case int64, float64:
+		return int64(unsafe.Sizeof(int64(0)))
+	default:
+		return 0
+	}
+}
+
+func (b *bitmasked) estimatedSize() int64 {
+	const interfaceSize = int64(unsafe.Sizeof(node(nil)))
+
+	if b.isSmall() {
+		size := int64(unsafe.Sizeof(*b)) + int64(len(b.small))*int64(unsafe.Sizeof(b))
+		for _, v := range b.small {
+			size += v.estimatedSize()
+		}
+
+		return size
+	}
+
+	size := int64(unsafe.Sizeof(*b)) + int64(unsafe.Sizeof(*b.values)) + int64(b.values.Len())*interfaceSize
+
+	for _, n := range b.values.Values() {
+		switch t := n.(type) {
+		case *value:
+			size += t.estimatedSize()
+		case *collision:
+			for _, v := range t.values {
+				size += interfaceSize + v.estimatedSize()
+			}
+		case *bitmasked:
+			size += t.estimatedSize()
+		}
+	}
+
+	return size
+}
+
+func (v *value) estimatedSize() int64 {
+	return int64(unsafe.Sizeof(*v)) + int64(len(v.key.key)) + estimatedSize(v.value)
+}
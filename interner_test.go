@@ -0,0 +1,132 @@
+package jsonchamp
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+func TestInternerDeduplicatesDecodedDocuments(t *testing.T) {
+	t.Parallel()
+
+	in := NewInterner()
+
+	docs := make([]*Map, 3)
+	for i := range docs {
+		doc := New(WithInterner(in))
+
+		data := fmt.Sprintf(`{"id":%d,"address":{"street":"Main","city":"Oslo"},"tags":["a","b"]}`, i)
+		if err := json.Unmarshal([]byte(data), doc); err != nil {
+			t.Fatal(err)
+		}
+
+		docs[i] = doc
+	}
+
+	first, _ := docs[0].GetMap("address")
+	for _, doc := range docs[1:] {
+		if address, _ := doc.GetMap("address"); address != first {
+			t.Errorf("address was not shared between documents")
+		}
+
+		if tags, _ := doc.GetList("tags"); tags != mustGetList(t, docs[0], "tags") {
+			t.Errorf("tags were not shared between documents")
+		}
+	}
+
+	stats := in.Stats()
+	if stats.Maps != 1 || stats.Arrays != 1 {
+		t.Errorf("Stats() = %+v, want 1 map and 1 array", stats)
+	}
+
+	if stats.Hits == 0 || stats.BytesSaved <= 0 {
+		t.Errorf("Stats() = %+v, want hits and saved bytes", stats)
+	}
+}
+
+func TestInternerSet(t *testing.T) {
+	t.Parallel()
+
+	in := NewInterner()
+
+	a := New(WithInterner(in)).Set("settings", mustParse(t, `{"theme":"dark","size":12}`))
+	b := New(WithInterner(in)).Set("settings", mustParse(t, `{"size":12,"theme":"dark"}`))
+	c := New().Set("settings", mustParse(t, `{"size":12,"theme":"dark"}`))
+
+	aSettings, _ := a.GetMap("settings")
+	bSettings, _ := b.GetMap("settings")
+	cSettings, _ := c.GetMap("settings")
+
+	if aSettings != bSettings {
+		t.Errorf("equal maps set with the interner were not shared")
+	}
+
+	if aSettings == cSettings {
+		t.Errorf("map set without the interner was shared")
+	}
+
+	// Maps from the interner keep interning.
+	nested := aSettings.Set("colors", []any{"red", "blue"})
+	if colors, _ := nested.GetList("colors"); colors != in.Intern([]any{"red", "blue"}) {
+		t.Errorf("array set in an interned map was not interned")
+	}
+
+	if got := in.Intern(int64(3)); got != int64(3) {
+		t.Errorf("Intern(3) = %v, want 3", got)
+	}
+
+	if !aSettings.Equals(cSettings) {
+		t.Errorf("interned map is not equal to the original")
+	}
+}
+
+func mustGetList(t *testing.T, m *Map, key string) *List {
+	t.Helper()
+
+	l, err := m.GetList(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return l
+}
+
+func TestInternerVerifiesDigestHits(t *testing.T) {
+	t.Parallel()
+
+	in := NewInterner()
+
+	first := in.Intern(mustParse(t, `{"a":1}`)).(*Map)
+	firstList := in.Intern([]any{"a"}).(*List)
+
+	// Digests are not collision resistant, so values with the same digest are compared before they are shared.
+	other := mustParse(t, `{"a":2}`)
+	collision := first.contentDigest()
+	other.root.digest.Store(&collision)
+
+	otherList := NewList("b")
+	listCollision := firstList.contentDigest()
+	otherList.digest.Store(&listCollision)
+
+	if got := in.Intern(other).(*Map); got == first || !got.Equals(other) {
+		t.Errorf("Intern() of a map with a colliding digest = %v, want %v", got, other)
+	}
+
+	if got := in.Intern(otherList); got != otherList {
+		t.Errorf("Intern() of a list with a colliding digest = %v, want the list itself", got)
+	}
+
+	// Floats that Equals considers equal are not interned as each other.
+	tenth := 0.1
+	if got := in.Intern([]any{tenth + 0.2}); in.Intern([]any{0.3}) == got {
+		t.Errorf("Intern() shared lists with different floats")
+	}
+
+	if got := in.Intern(mustParse(t, `{"a":1}`)); got != first {
+		t.Errorf("Intern() of an equal map = %v, want the interned map", got)
+	}
+
+	if stats := in.Stats(); stats.Maps != 2 || stats.Arrays != 4 {
+		t.Errorf("Stats() = %+v, want 2 maps and 4 arrays", stats)
+	}
+}
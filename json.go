@@ -41,6 +41,23 @@ func marshalValue(m any) ([]byte, error) {
 		if !strings.Contains(buf.String(), ".") {
 			buf.WriteString(".0")
 		}
+	case *List:
+		buf.WriteString("[")
+
+		for i, item := range v.All() {
+			valMarshal, err := marshalValue(item)
+			if err != nil {
+				return nil, fmt.Errorf("could not marshal list item: %w", err)
+			}
+
+			buf.Write(valMarshal)
+
+			if i < v.Len()-1 {
+				buf.WriteString(",")
+			}
+		}
+
+		buf.WriteString("]")
 	case *Map:
 		buf.WriteString("{")
 
@@ -118,7 +135,7 @@ func (m *Map) MarshalJSON() ([]byte, error) {
 	return b, nil
 }
 
-func unmarshalArray(dec *json.Decoder) ([]any, error) {
+func unmarshalArray(dec *json.Decoder, in *Interner) (*List, error) {
 	var arr []any
 
 	for {
@@ -128,14 +145,14 @@ func unmarshalArray(dec *json.Decoder) ([]any, error) {
 		}
 
 		if token == json.Delim(']') {
-			return arr, nil
+			return newListFromValues(arr), nil
 		}
 
 		switch v := token.(type) {
 		case json.Delim:
 			switch v {
 			case '{':
-				newMap := New()
+				newMap := New(WithInterner(in))
 
 				newMap, err := unmarshalMap(dec, newMap)
 				if err != nil {
@@ -144,7 +161,7 @@ func unmarshalArray(dec *json.Decoder) ([]any, error) {
 
 				arr = append(arr, newMap)
 			case '[':
-				newArr, err := unmarshalArray(dec)
+				newArr, err := unmarshalArray(dec, in)
 				if err != nil {
 					return nil, fmt.Errorf("could not unmarshal array: %w", err)
 				}
@@ -173,6 +190,8 @@ func unmarshalArray(dec *json.Decoder) ([]any, error) {
 			}
 		case bool:
 			arr = append(arr, v)
+		case nil:
+			arr = append(arr, nil)
 		default:
 			return nil, fmt.Errorf("unexpected type %T", v)
 		}
@@ -221,10 +240,12 @@ func unmarshalMap(dec *json.Decoder, m *Map) (*Map, error) {
 			}
 		case bool:
 			m = m.Set(keyString, v)
+		case nil:
+			m = m.Set(keyString, nil)
 		case json.Delim:
 			switch v {
 			case '{':
-				newMap := New()
+				newMap := New(WithInterner(m.interner))
 
 				newMap, err := unmarshalMap(dec, newMap)
 				if err != nil {
@@ -233,7 +254,7 @@ func unmarshalMap(dec *json.Decoder, m *Map) (*Map, error) {
 
 				m = m.Set(keyString, newMap)
 			case '[':
-				arr, err := unmarshalArray(dec)
+				arr, err := unmarshalArray(dec, m.interner)
 				if err != nil {
 					return nil, fmt.Errorf("could not unmarshal array: %w", err)
 				}
@@ -250,17 +271,8 @@ func unmarshalMap(dec *json.Decoder, m *Map) (*Map, error) {
 
 // UnmarshalJSON unmarshals a JSON object into a Map.
 func (m *Map) UnmarshalJSON(d []byte) error {
-	if m.hasher == nil {
-		m.hasher = defaultMapOptions.hasher()
-	}
-
 	if m.root == nil {
-		m.root = &bitmasked{
-			level:      0,
-			valueMap:   0,
-			subMapsMap: 0,
-			values:     newCowSlice(),
-		}
+		m.root = newRootNode()
 	}
 
 	dec := json.NewDecoder(bytes.NewReader(d))
@@ -0,0 +1,71 @@
+package jsonchamp
+
+import (
+	goSlices "slices"
+)
+
+// Lens reads and updates the value of type T at a path in a map.
+// Values are cast to T like in Get, so a Lens[int64] reads JSON numbers without fractions
+// and a Lens[*Map] reads nested objects.
+type Lens[T any] struct {
+	path Path
+}
+
+// At returns a lens for the value at the keys. Keys of digits also select elements of arrays.
+func At[T any](keys ...string) Lens[T] {
+	path := make(Path, len(keys))
+	for i, key := range keys {
+		path[i] = KeySegment(key)
+	}
+
+	return Lens[T]{path: path}
+}
+
+// PathLens returns a lens for the value at the path.
+func PathLens[T any](path Path) Lens[T] {
+	return Lens[T]{path: goSlices.Clone(path)}
+}
+
+// Then returns a lens for the value at the path of inner, starting from the value at the path of outer.
+func Then[U any, T any](outer Lens[T], inner Lens[U]) Lens[U] {
+	return Lens[U]{path: goSlices.Concat(outer.path, inner.path)}
+}
+
+// Path returns the path of the lens.
+func (l Lens[T]) Path() Path {
+	return goSlices.Clone(l.path)
+}
+
+// Get returns the value at the path of the lens.
+// It returns ErrKeyNotFound if there is no value at the path and ErrWrongType if the value is not a T.
+func (l Lens[T]) Get(m *Map) (T, error) {
+	return Get[T](m, l.path)
+}
+
+// Set returns a new map with the value at the path of the lens replaced. Missing maps on the path are created.
+// If the path goes through a value that is not a map or array, or an array index that does not exist,
+// the map is returned unchanged.
+func (l Lens[T]) Set(m *Map, value T) *Map {
+	updated, ok := setPath(m, l.path, value)
+	if !ok {
+		return m
+	}
+
+	updatedMap, ok := updated.(*Map)
+	if !ok {
+		return m
+	}
+
+	return updatedMap
+}
+
+// Modify returns a new map with the value at the path of the lens replaced by the result of fn.
+// If the value cannot be read by Get, the map is returned unchanged.
+func (l Lens[T]) Modify(m *Map, fn func(T) T) *Map {
+	v, err := l.Get(m)
+	if err != nil {
+		return m
+	}
+
+	return l.Set(m, fn(v))
+}
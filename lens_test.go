@@ -0,0 +1,111 @@
+package jsonchamp
+
+import (
+	"errors"
+	"hash/fnv"
+	"strings"
+	"testing"
+)
+
+func TestLensGet(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"spec":{"image":{"tag":"v1"},"ports":[{"port":80}]}}`)
+
+	if tag, err := At[string]("spec", "image", "tag").Get(m); err != nil || tag != "v1" {
+		t.Errorf("Get() = %v, %v, want v1", tag, err)
+	}
+
+	port := Then(At[*List]("spec", "ports"), PathLens[int64](MustParsePath("0.port")))
+	if got, err := port.Get(m); err != nil || got != 80 {
+		t.Errorf("Get() = %v, %v, want 80", got, err)
+	}
+
+	if got := port.Path().String(); got != "spec.ports.0.port" {
+		t.Errorf("Path() = %s, want spec.ports.0.port", got)
+	}
+
+	if _, err := At[string]("spec", "missing").Get(m); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Get() error = %v, want %v", err, ErrKeyNotFound)
+	}
+
+	if _, err := At[int64]("spec", "image", "tag").Get(m); !errors.Is(err, ErrWrongType) {
+		t.Errorf("Get() error = %v, want %v", err, ErrWrongType)
+	}
+}
+
+func TestLensSetAndModify(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"spec":{"image":{"tag":"v1"},"ports":[{"port":80}]},"other":{}}`)
+	tag := At[string]("spec", "image", "tag")
+
+	tests := []struct {
+		name string
+		got  *Map
+		want string
+	}{
+		{
+			name: "set",
+			got:  tag.Set(m, "v2"),
+			want: `{"spec":{"image":{"tag":"v2"},"ports":[{"port":80}]},"other":{}}`,
+		},
+		{
+			name: "modify",
+			got:  tag.Modify(m, strings.ToUpper),
+			want: `{"spec":{"image":{"tag":"V1"},"ports":[{"port":80}]},"other":{}}`,
+		},
+		{
+			name: "through array",
+			got:  At[int64]("spec", "ports", "0", "port").Modify(m, func(p int64) int64 { return p + 1 }),
+			want: `{"spec":{"image":{"tag":"v1"},"ports":[{"port":81}]},"other":{}}`,
+		},
+		{
+			name: "create missing maps",
+			got:  At[bool]("spec", "new", "flag").Set(m, true),
+			want: `{"spec":{"image":{"tag":"v1"},"ports":[{"port":80}],"new":{"flag":true}},"other":{}}`,
+		},
+		{
+			name: "missing array index",
+			got:  At[int64]("spec", "ports", "5", "port").Set(m, 1),
+			want: `{"spec":{"image":{"tag":"v1"},"ports":[{"port":80}]},"other":{}}`,
+		},
+		{
+			name: "modify missing value",
+			got:  At[string]("spec", "missing").Modify(m, strings.ToUpper),
+			want: `{"spec":{"image":{"tag":"v1"},"ports":[{"port":80}]},"other":{}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if want := mustParse(t, tt.want); !tt.got.Equals(want) {
+				t.Errorf("got %v, want %v", tt.got.ToMap(), want.ToMap())
+			}
+		})
+	}
+
+	if got, _ := tag.Get(m); got != "v1" {
+		t.Errorf("original map was modified: tag = %s", got)
+	}
+}
+
+func TestLensSetKeepsMapOptions(t *testing.T) {
+	t.Parallel()
+
+	in := NewInterner()
+	m := New(WithHasher(fnv.New64a), WithInterner(in))
+
+	updated := At[string]("a", "b", "c").Set(m, "x")
+
+	a, _ := updated.GetMap("a")
+	b, _ := a.GetMap("b")
+
+	for _, created := range []*Map{a, b} {
+		if created.hasher != m.hasher || created.interner != in {
+			t.Errorf("created map does not have the hasher and interner of its parent")
+		}
+	}
+}
@@ -0,0 +1,456 @@
+package jsonchamp
+
+import (
+	"fmt"
+	"iter"
+	goSlices "slices"
+	"sync/atomic"
+)
+
+const (
+	listBits  = 5
+	listWidth = 1 << listBits
+	listMask  = listWidth - 1
+)
+
+// listNode is a node in the trie of a List. Leaf nodes hold values and branch nodes hold child nodes.
+type listNode struct {
+	children []*listNode
+	values   []any
+	// digest caches the content digest of the node, so after an update only the nodes on the path to
+	// the changed element are hashed again.
+	digest atomic.Pointer[digest]
+}
+
+func newListLeaf(values []any) *listNode {
+	return &listNode{children: nil, values: values, digest: atomic.Pointer[digest]{}}
+}
+
+func newListBranch(children []*listNode) *listNode {
+	return &listNode{children: children, values: nil, digest: atomic.Pointer[digest]{}}
+}
+
+// List is an immutable vector used to store JSON arrays.
+// It is a 32-way trie with the last, partially filled leaf kept separately as the tail,
+// so appending and updating an element only copies the path to that element.
+// Versions of a list share all nodes that were not modified.
+type List struct {
+	count int
+	shift uint
+	root  *listNode
+	tail  []any
+	// digest caches the content digest of the list.
+	digest atomic.Pointer[digest]
+}
+
+var emptyListNode = newListLeaf(nil)
+
+// NewList creates a new list with the given items. The items are normalized like the values of a Map.
+func NewList(items ...any) *List {
+	normalized := make([]any, len(items))
+	for i, item := range items {
+		normalized[i] = normalizeValue(item)
+	}
+
+	return newListFromValues(normalized)
+}
+
+// newListFromValues builds a list from values that are already normalized.
+// The leaves are filled directly instead of appending one value at a time.
+func newListFromValues(values []any) *List {
+	tailStart := 0
+	if len(values) > 0 {
+		tailStart = ((len(values) - 1) >> listBits) << listBits
+	}
+
+	leaves := make([]*listNode, 0, tailStart/listWidth)
+	for start := 0; start < tailStart; start += listWidth {
+		leaves = append(leaves, newListLeaf(goSlices.Clone(values[start:start+listWidth])))
+	}
+
+	return newListFromLeaves(leaves, goSlices.Clone(values[tailStart:]))
+}
+
+// newListFromLeaves builds a list from full leaves followed by the tail, which must not be empty
+// unless there are no leaves. The leaves are shared with the new list.
+func newListFromLeaves(leaves []*listNode, tail []any) *List {
+	nodes := leaves
+	shift := uint(listBits)
+
+	for len(nodes) > listWidth {
+		parents := make([]*listNode, 0, (len(nodes)+listWidth-1)/listWidth)
+		for start := 0; start < len(nodes); start += listWidth {
+			end := min(start+listWidth, len(nodes))
+			parents = append(parents, newListBranch(goSlices.Clone(nodes[start:end])))
+		}
+
+		nodes = parents
+		shift += listBits
+	}
+
+	root := emptyListNode
+	if len(nodes) > 0 {
+		root = newListBranch(goSlices.Clone(nodes))
+	}
+
+	return &List{
+		count:  len(leaves)*listWidth + len(tail),
+		shift:  shift,
+		root:   root,
+		tail:   tail,
+		digest: atomic.Pointer[digest]{},
+	}
+}
+
+// Len returns the number of elements in the list.
+func (l *List) Len() int {
+	return l.count
+}
+
+func (l *List) tailOffset() int {
+	if l.count < listWidth {
+		return 0
+	}
+
+	return ((l.count - 1) >> listBits) << listBits
+}
+
+// leafFor returns the values of the leaf holding index i.
+func (l *List) leafFor(i int) []any {
+	if i >= l.tailOffset() {
+		return l.tail
+	}
+
+	return l.leafNode(i).values
+}
+
+// Get returns the element at index i. It returns false if i is out of range.
+func (l *List) Get(i int) (any, bool) {
+	if i < 0 || i >= l.count {
+		return nil, false
+	}
+
+	return l.at(i), true
+}
+
+// at returns the element at index i, which must be in range.
+func (l *List) at(i int) any {
+	return l.leafFor(i)[i&listMask]
+}
+
+// Set returns a new list with the element at index i replaced by the value.
+// It panics if i is out of range.
+func (l *List) Set(i int, value any) *List {
+	if i < 0 || i >= l.count {
+		panic(fmt.Sprintf("list index out of range [%d] with length %d", i, l.count))
+	}
+
+	value = normalizeValue(value)
+
+	if i >= l.tailOffset() {
+		newTail := goSlices.Clone(l.tail)
+		newTail[i&listMask] = value
+
+		return &List{
+			count:  l.count,
+			shift:  l.shift,
+			root:   l.root,
+			tail:   newTail,
+			digest: atomic.Pointer[digest]{},
+		}
+	}
+
+	return &List{
+		count:  l.count,
+		shift:  l.shift,
+		root:   setInNode(l.root, l.shift, i, value),
+		tail:   l.tail,
+		digest: atomic.Pointer[digest]{},
+	}
+}
+
+func setInNode(node *listNode, level uint, i int, value any) *listNode {
+	if level == 0 {
+		values := goSlices.Clone(node.values)
+		values[i&listMask] = value
+
+		return newListLeaf(values)
+	}
+
+	children := goSlices.Clone(node.children)
+	sub := (i >> level) & listMask
+	children[sub] = setInNode(children[sub], level-listBits, i, value)
+
+	return newListBranch(children)
+}
+
+// Append returns a new list with the values added at the end.
+func (l *List) Append(values ...any) *List {
+	normalized := make([]any, len(values))
+	for i, v := range values {
+		normalized[i] = normalizeValue(v)
+	}
+
+	return l.appendValues(normalized)
+}
+
+// appendValues adds values that are already normalized. The tail is filled up and pushed into the trie
+// a leaf at a time.
+func (l *List) appendValues(values []any) *List {
+	if len(values) == 0 {
+		return l
+	}
+
+	root, shift, size := l.root, l.shift, l.tailOffset()
+
+	n := min(listWidth-len(l.tail), len(values))
+	tail := append(goSlices.Clip(l.tail), values[:n]...)
+
+	for values = values[n:]; len(values) > 0; values = values[n:] {
+		root, shift = pushLeaf(root, shift, size, newListLeaf(tail))
+		size += listWidth
+
+		n = min(listWidth, len(values))
+		tail = goSlices.Clone(values[:n])
+	}
+
+	return &List{
+		count:  size + len(tail),
+		shift:  shift,
+		root:   root,
+		tail:   tail,
+		digest: atomic.Pointer[digest]{},
+	}
+}
+
+// pushLeaf returns the root and shift of the trie after adding a full leaf to a trie with size elements.
+func pushLeaf(root *listNode, shift uint, size int, leaf *listNode) (*listNode, uint) {
+	if (size>>listBits)+1 > 1<<shift {
+		// The trie is full, so it grows a level.
+		return newListBranch([]*listNode{root, newListPath(shift, leaf)}), shift + listBits
+	}
+
+	return pushTail(shift, root, size+listWidth-1, leaf), shift
+}
+
+// pushTail adds the leaf holding index last below the parent.
+func pushTail(level uint, parent *listNode, last int, leaf *listNode) *listNode {
+	sub := (last >> level) & listMask
+
+	children := make([]*listNode, len(parent.children), max(len(parent.children), sub+1))
+	copy(children, parent.children)
+
+	var child *listNode
+
+	switch {
+	case level == listBits:
+		child = leaf
+	case sub < len(parent.children):
+		child = pushTail(level-listBits, parent.children[sub], last, leaf)
+	default:
+		child = newListPath(level-listBits, leaf)
+	}
+
+	if sub < len(children) {
+		children[sub] = child
+	} else {
+		children = append(children, child)
+	}
+
+	return newListBranch(children)
+}
+
+func newListPath(level uint, node *listNode) *listNode {
+	if level == 0 {
+		return node
+	}
+
+	return newListBranch([]*listNode{newListPath(level-listBits, node)})
+}
+
+// Slice returns a new list with the elements from start up to, but not including, end.
+// It panics if the range is invalid.
+//
+// The elements are stored at their index in the trie, so only a slice from a start that is a multiple
+// of 32 can share the leaves of the list. A slice from the start shares all nodes before end.
+// Other slices copy the selected elements.
+func (l *List) Slice(start int, end int) *List {
+	if start < 0 || end > l.count || start > end {
+		panic(fmt.Sprintf("list slice bounds out of range [%d:%d] with length %d", start, end, l.count))
+	}
+
+	switch {
+	case start == 0 && end == l.count:
+		return l
+	case start == end:
+		return newListFromValues(nil)
+	case start == 0:
+		return l.take(end)
+	case start&listMask == 0:
+		tailStart := start + ((end-start-1)>>listBits)<<listBits
+
+		leaves := make([]*listNode, 0, (tailStart-start)/listWidth)
+		for i := start; i < tailStart; i += listWidth {
+			leaves = append(leaves, l.leafNode(i))
+		}
+
+		return newListFromLeaves(leaves, goSlices.Clone(l.leafFor(tailStart)[:end-tailStart]))
+	default:
+		return newListFromValues(l.valuesBetween(start, end))
+	}
+}
+
+// take returns a list with the first n elements, which shares all nodes of the trie before them.
+func (l *List) take(n int) *List {
+	tailStart := ((n - 1) >> listBits) << listBits
+	tail := goSlices.Clone(l.leafFor(tailStart)[:n-tailStart])
+
+	if tailStart == 0 {
+		return newListFromLeaves(nil, tail)
+	}
+
+	root, shift := trimNode(l.root, l.shift, tailStart-1), l.shift
+	for shift > listBits && len(root.children) == 1 {
+		root = root.children[0]
+		shift -= listBits
+	}
+
+	return &List{
+		count:  n,
+		shift:  shift,
+		root:   root,
+		tail:   tail,
+		digest: atomic.Pointer[digest]{},
+	}
+}
+
+// trimNode returns the node without the leaves after the one holding index last.
+func trimNode(node *listNode, level uint, last int) *listNode {
+	sub := (last >> level) & listMask
+	if level == listBits {
+		if sub+1 == len(node.children) {
+			return node
+		}
+
+		return newListBranch(goSlices.Clone(node.children[:sub+1]))
+	}
+
+	child := trimNode(node.children[sub], level-listBits, last)
+	if child == node.children[sub] && sub+1 == len(node.children) {
+		return node
+	}
+
+	children := goSlices.Clone(node.children[:sub+1])
+	children[sub] = child
+
+	return newListBranch(children)
+}
+
+// leafNode returns the trie leaf holding index i, which must be before the tail.
+func (l *List) leafNode(i int) *listNode {
+	node := l.root
+	for level := l.shift; level > 0; level -= listBits {
+		node = node.children[(i>>level)&listMask]
+	}
+
+	return node
+}
+
+// valuesBetween returns the elements from start up to, but not including, end as a new slice.
+func (l *List) valuesBetween(start int, end int) []any {
+	values := make([]any, 0, end-start)
+	for i := start; i < end; i = (i | listMask) + 1 {
+		leaf := l.leafFor(i)
+		values = append(values, leaf[i&listMask:min(len(leaf), end-i+i&listMask)]...)
+	}
+
+	return values
+}
+
+// without returns a new list without the element at index i. The nodes before i are shared
+// and the elements after it are copied, as they move down one index.
+func (l *List) without(i int) *List {
+	if i == 0 {
+		return newListFromValues(l.valuesBetween(1, l.count))
+	}
+
+	return l.take(i).appendValues(l.valuesBetween(i+1, l.count))
+}
+
+// Concat returns a new list with the elements of other added at the end.
+// The nodes of the receiver are shared with the new list. If the length of the receiver is a multiple
+// of 32, the leaves of other are shared too, otherwise its elements are copied.
+func (l *List) Concat(other *List) *List {
+	switch {
+	case other.count == 0:
+		return l
+	case l.count == 0:
+		return other
+	case l.count&listMask != 0:
+		return l.appendValues(other.Values())
+	}
+
+	root, shift := pushLeaf(l.root, l.shift, l.tailOffset(), newListLeaf(l.tail))
+	size := l.count
+
+	for i := 0; i < other.tailOffset(); i += listWidth {
+		root, shift = pushLeaf(root, shift, size, other.leafNode(i))
+		size += listWidth
+	}
+
+	return &List{
+		count:  size + len(other.tail),
+		shift:  shift,
+		root:   root,
+		tail:   other.tail,
+		digest: atomic.Pointer[digest]{},
+	}
+}
+
+// All returns an iterator over the indices and elements of the list.
+func (l *List) All() iter.Seq2[int, any] {
+	return func(yield func(int, any) bool) {
+		for start := 0; start < l.count; start += listWidth {
+			for offset, v := range l.leafFor(start) {
+				if !yield(start+offset, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Values returns the elements of the list as a new slice.
+func (l *List) Values() []any {
+	values := make([]any, 0, l.count)
+	for _, v := range l.All() {
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// Equals compares two lists element by element and returns true if they are equal.
+func (l *List) Equals(other *List) bool {
+	if l.count != other.count {
+		return false
+	}
+
+	if l == other {
+		return true
+	}
+
+	for i, v := range l.All() {
+		otherValue, _ := other.Get(i)
+		if !equalsAny(v, otherValue) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MarshalJSON marshals a List into a JSON array.
+func (l *List) MarshalJSON() ([]byte, error) {
+	return marshalValue(l)
+}
@@ -0,0 +1,223 @@
+package jsonchamp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestListAppendAndGet(t *testing.T) {
+	t.Parallel()
+
+	sizes := []int{0, 1, 31, 32, 33, 64, 1024, 1056, 1057, 32*32*32 + 33}
+
+	for _, size := range sizes {
+		appended := NewList()
+		values := make([]any, 0, size)
+
+		for i := range size {
+			appended = appended.Append(i)
+			values = append(values, int64(i))
+		}
+
+		built := newListFromValues(values)
+
+		for _, l := range []*List{appended, built} {
+			if l.Len() != size {
+				t.Fatalf("size %d: expected length %d, got %d", size, size, l.Len())
+			}
+
+			for i := range size {
+				v, ok := l.Get(i)
+				if !ok || v != int64(i) {
+					t.Fatalf("size %d: expected %d at index %d, got %v", size, i, i, v)
+				}
+			}
+
+			if _, ok := l.Get(size); ok {
+				t.Fatalf("size %d: expected index %d to be out of range", size, size)
+			}
+		}
+
+		// Appending to a list built from values must continue the same layout.
+		next := built.Append("next")
+		if v, _ := next.Get(size); v != "next" {
+			t.Fatalf("size %d: expected appended value, got %v", size, v)
+		}
+	}
+}
+
+func TestListSetIsPersistent(t *testing.T) {
+	t.Parallel()
+
+	original := NewList()
+	for i := range 100 {
+		original = original.Append(i)
+	}
+
+	updated := original.Set(5, "five").Set(99, "last")
+
+	if v, _ := original.Get(5); v != int64(5) {
+		t.Errorf("original list was modified at index 5: %v", v)
+	}
+
+	if v, _ := original.Get(99); v != int64(99) {
+		t.Errorf("original list was modified at index 99: %v", v)
+	}
+
+	if v, _ := updated.Get(5); v != "five" {
+		t.Errorf("expected updated value at index 5, got %v", v)
+	}
+
+	if v, _ := updated.Get(99); v != "last" {
+		t.Errorf("expected updated value at index 99, got %v", v)
+	}
+
+	// Appending to two versions with the same tail must not interfere.
+	a := original.Append("a")
+	b := original.Append("b")
+
+	if v, _ := a.Get(100); v != "a" {
+		t.Errorf("expected a, got %v", v)
+	}
+
+	if v, _ := b.Get(100); v != "b" {
+		t.Errorf("expected b, got %v", v)
+	}
+}
+
+func TestListSliceAndConcat(t *testing.T) {
+	t.Parallel()
+
+	l := NewList(1, 2, 3, 4, 5)
+
+	if got := l.Slice(1, 4); !got.Equals(NewList(2, 3, 4)) {
+		t.Errorf("Slice() = %v, want [2,3,4]", got.Values())
+	}
+
+	if got := l.Slice(2, 2); got.Len() != 0 {
+		t.Errorf("expected empty slice, got %v", got.Values())
+	}
+
+	if got := NewList(1, 2).Concat(NewList(3)); !got.Equals(NewList(1, 2, 3)) {
+		t.Errorf("Concat() = %v, want [1,2,3]", got.Values())
+	}
+}
+
+func TestListSliceConcatAndWithoutShapes(t *testing.T) {
+	t.Parallel()
+
+	values := make([]any, 1100)
+	for i := range values {
+		values[i] = int64(i)
+	}
+
+	l := NewList(values...)
+
+	// The digest depends on the shape of the trie, so equal digests show the lists are built like NewList.
+	check := func(t *testing.T, got *List, want []any) {
+		t.Helper()
+
+		if expected := NewList(want...); !got.Equals(expected) || got.contentDigest() != expected.contentDigest() {
+			t.Errorf("got %d elements with a different shape than NewList of %d elements", got.Len(), len(want))
+		}
+	}
+
+	bounds := []int{0, 1, 31, 32, 33, 64, 100, 1024, 1055, 1056, 1057, 1099, 1100}
+
+	for _, start := range bounds {
+		for _, end := range bounds {
+			if start > end {
+				continue
+			}
+
+			check(t, l.Slice(start, end), values[start:end])
+			check(t, l.Slice(0, start).Concat(l.Slice(start, end)), values[:end])
+		}
+
+		if start < len(values) {
+			check(t, l.without(start), append(append([]any{}, values[:start]...), values[start+1:]...))
+		}
+	}
+}
+
+func TestListSliceAndConcatShareNodes(t *testing.T) {
+	t.Parallel()
+
+	values := make([]any, 2000)
+	for i := range values {
+		values[i] = int64(i)
+	}
+
+	l := NewList(values...)
+
+	prefix := l.Slice(0, 1500)
+	if prefix.root.children[0] != l.root.children[0] {
+		t.Errorf("Slice() from the start did not share the first sub trie")
+	}
+
+	suffix := l.Slice(64, 2000)
+	if suffix.leafNode(0) != l.leafNode(64) {
+		t.Errorf("Slice() from a multiple of 32 did not share the leaves")
+	}
+
+	joined := prefix.Slice(0, 1024).Concat(suffix)
+	if joined.leafNode(0) != l.leafNode(0) || joined.leafNode(1024) != l.leafNode(64) {
+		t.Errorf("Concat() did not share the nodes of both lists")
+	}
+}
+
+func TestListNormalizesValues(t *testing.T) {
+	t.Parallel()
+
+	l := NewList(1, []int{2, 3}, NewFromItems("a", 1))
+
+	if v, _ := l.Get(0); v != int64(1) {
+		t.Errorf("expected int64, got %T", v)
+	}
+
+	if v, _ := l.Get(1); !equalsAny(v, NewList(2, 3)) {
+		t.Errorf("expected nested list, got %T", v)
+	}
+}
+
+func TestListJSON(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":[1,"b",[true,null],{"c":1.5}]}`)
+
+	list, err := m.GetList("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if list.Len() != 4 {
+		t.Fatalf("expected 4 elements, got %d", list.Len())
+	}
+
+	out, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := `{"a":[1,"b",[true,null],{"c":1.5}]}`; string(out) != want {
+		t.Errorf("MarshalJSON() = %s, want %s", out, want)
+	}
+}
+
+func TestToStructWithListField(t *testing.T) {
+	t.Parallel()
+
+	type testStruct struct {
+		Tags   []string `champ:"tags"`
+		Counts []int    `champ:"counts"`
+	}
+
+	var s testStruct
+	if err := ToStruct(NewFromItems("tags", []any{"a", "b"}, "counts", []any{1, 2}), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Tags) != 2 || s.Tags[1] != "b" || len(s.Counts) != 2 || s.Counts[1] != 2 {
+		t.Errorf("unexpected struct: %+v", s)
+	}
+}
@@ -0,0 +1,349 @@
+package jsonchamp
+
+import (
+	"fmt"
+	goSlices "slices"
+)
+
+// ArrayStrategy decides how two arrays at the same path are merged.
+type ArrayStrategy int
+
+const (
+	// ArrayReplace replaces the current array with the other array.
+	ArrayReplace ArrayStrategy = iota
+	// ArrayAppend appends the elements of the other array to the current array.
+	ArrayAppend
+	// ArrayUnion appends the elements of the other array that are not already in the current array.
+	ArrayUnion
+	// ArrayMergeByID merges elements that are maps with the same identity field value and appends the rest.
+	// The identity field is set with WithArrayIdentity.
+	ArrayMergeByID
+)
+
+// NullStrategy decides what a nil value in the other map means.
+type NullStrategy int
+
+const (
+	// NullSet stores nil as a value, like any other value.
+	NullSet NullStrategy = iota
+	// NullDelete removes the key from the merged map.
+	NullDelete
+)
+
+// MergeReport describes the effects of a merge.
+type MergeReport struct {
+	// Overwritten lists the paths where an existing value was replaced by a different value.
+	Overwritten []Path
+}
+
+type mergeStrategy struct {
+	arrays      ArrayStrategy
+	identityKey string
+	nulls       NullStrategy
+	strictTypes bool
+}
+
+type mergeOptions struct {
+	strategy     mergeStrategy
+	paths        map[string][]MergeOption
+	identityKeys IdentityKeys
+}
+
+// MergeOption is a function that sets an option on a merge.
+type MergeOption func(*mergeOptions)
+
+// WithArrayStrategy sets how arrays are merged.
+func WithArrayStrategy(s ArrayStrategy) MergeOption {
+	return func(o *mergeOptions) {
+		o.strategy.arrays = s
+	}
+}
+
+// WithArrayIdentity merges arrays of maps by matching the value of the identity field.
+func WithArrayIdentity(field string) MergeOption {
+	return func(o *mergeOptions) {
+		o.strategy.arrays = ArrayMergeByID
+		o.strategy.identityKey = field
+	}
+}
+
+// WithMergeIdentityKeys merges the records of the arrays matched by the identity keys by their identity,
+// regardless of the array strategy.
+func WithMergeIdentityKeys(keys IdentityKeys) MergeOption {
+	return func(o *mergeOptions) {
+		o.identityKeys = append(o.identityKeys, keys...)
+	}
+}
+
+// WithNullStrategy sets how nil values in the other map are handled.
+func WithNullStrategy(s NullStrategy) MergeOption {
+	return func(o *mergeOptions) {
+		o.strategy.nulls = s
+	}
+}
+
+// WithTypeMismatchError makes the merge fail when a value would be replaced by a value of another JSON type.
+// Replacing a value with nil is not a type mismatch, and a nil value is replaced like a missing one,
+// so a null can be filled in with a value of any type.
+func WithTypeMismatchError() MergeOption {
+	return func(o *mergeOptions) {
+		o.strategy.strictTypes = true
+	}
+}
+
+// AtPath applies the options to the value at the path and everything below it.
+// Options for a longer path take precedence over options for a shorter one.
+func AtPath(path Path, opts ...MergeOption) MergeOption {
+	return func(o *mergeOptions) {
+		if o.paths == nil {
+			o.paths = make(map[string][]MergeOption)
+		}
+
+		k := mergePathKey(path)
+		o.paths[k] = append(o.paths[k], opts...)
+	}
+}
+
+func mergePathKey(path Path) string {
+	return path.String()
+}
+
+// strategyAt returns the strategy for the path, given the strategy inherited from its parent.
+func (o mergeOptions) strategyAt(path Path, inherited mergeStrategy) mergeStrategy {
+	opts, ok := o.paths[mergePathKey(path)]
+	if !ok {
+		return inherited
+	}
+
+	scoped := mergeOptions{strategy: inherited, paths: nil, identityKeys: nil}
+	for _, opt := range opts {
+		opt(&scoped)
+	}
+
+	return scoped.strategy
+}
+
+// MergeWith merges two maps using the given options.
+// Without options it behaves like Merge: maps are merged recursively and any other value from the other map
+// replaces the current value.
+// It returns an error wrapping ErrTypeMismatch if WithTypeMismatchError is set and the types of a value differ.
+func (m *Map) MergeWith(other *Map, opts ...MergeOption) (*Map, MergeReport, error) {
+	var options mergeOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var report MergeReport
+
+	merged, err := mergeMaps(nil, m, other, options.strategy, options, &report)
+	if err != nil {
+		return nil, MergeReport{Overwritten: nil}, err
+	}
+
+	return merged, report, nil
+}
+
+func mergeMaps(
+	path Path,
+	m *Map,
+	other *Map,
+	inherited mergeStrategy,
+	options mergeOptions,
+	report *MergeReport,
+) (*Map, error) {
+	result := m
+
+	keys := other.Keys()
+	goSlices.Sort(keys)
+
+	for _, k := range keys {
+		keyPath := path.Child(k)
+		strategy := options.strategyAt(keyPath, inherited)
+
+		otherValue, _ := other.Get(k)
+		currentValue, currentExists := result.Get(k)
+
+		if otherValue == nil && strategy.nulls == NullDelete {
+			if currentExists {
+				result, _ = result.Delete(k)
+				report.Overwritten = append(report.Overwritten, keyPath)
+			}
+
+			continue
+		}
+
+		if !currentExists {
+			if otherMap, ok := otherValue.(*Map); ok {
+				otherValue = withoutDeletedNulls(keyPath, otherMap, strategy, options)
+			}
+
+			result = result.Set(k, otherValue)
+
+			continue
+		}
+
+		merged, err := mergeValues(keyPath, currentValue, otherValue, strategy, options, report)
+		if err != nil {
+			return nil, err
+		}
+
+		result = result.Set(k, merged)
+	}
+
+	return result, nil
+}
+
+// withoutDeletedNulls removes the keys with nil values from a map added by a merge and from the maps nested in it,
+// where NullDelete is in effect. It returns the map itself if there are none.
+func withoutDeletedNulls(path Path, m *Map, inherited mergeStrategy, options mergeOptions) *Map {
+	result := m
+
+	for _, k := range m.Keys() {
+		keyPath := path.Child(k)
+		strategy := options.strategyAt(keyPath, inherited)
+		v, _ := m.Get(k)
+
+		switch t := v.(type) {
+		case nil:
+			if strategy.nulls == NullDelete {
+				result, _ = result.Delete(k)
+			}
+		case *Map:
+			if stripped := withoutDeletedNulls(keyPath, t, strategy, options); stripped != t {
+				result = result.Set(k, stripped)
+			}
+		}
+	}
+
+	return result
+}
+
+func mergeValues(
+	path Path,
+	current any,
+	other any,
+	strategy mergeStrategy,
+	options mergeOptions,
+	report *MergeReport,
+) (any, error) {
+	if strategy.strictTypes && current != nil && other != nil && jsonKind(current) != jsonKind(other) {
+		return nil, fmt.Errorf(
+			"%w: '%s' is %s, got %s", ErrTypeMismatch, path, jsonKind(current), jsonKind(other),
+		)
+	}
+
+	currentMap, currentIsMap := current.(*Map)
+	otherMap, otherIsMap := other.(*Map)
+
+	if currentIsMap && otherIsMap {
+		return mergeMaps(path, currentMap, otherMap, strategy, options, report)
+	}
+
+	currentList, currentIsList := current.(*List)
+	otherList, otherIsList := other.(*List)
+
+	if currentIsList && otherIsList {
+		arrayStrategy := strategy
+		if field, ok := options.identityKeys.fieldFor(path); ok {
+			arrayStrategy.arrays = ArrayMergeByID
+			arrayStrategy.identityKey = field
+		}
+
+		if arrayStrategy.arrays != ArrayReplace {
+			return mergeArrays(path, currentList, otherList, arrayStrategy, strategy, options, report)
+		}
+	}
+
+	if !equalsAny(current, other) {
+		report.Overwritten = append(report.Overwritten, path)
+	}
+
+	return other, nil
+}
+
+// mergeArrays merges two arrays using the array strategy. Elements merged by identity use the item strategy.
+func mergeArrays(
+	path Path,
+	current *List,
+	other *List,
+	strategy mergeStrategy,
+	itemStrategy mergeStrategy,
+	options mergeOptions,
+	report *MergeReport,
+) (*List, error) {
+	result := current.Values()
+
+	for _, otherItem := range other.All() {
+		switch strategy.arrays {
+		case ArrayUnion:
+			if goSlices.IndexFunc(result, func(v any) bool { return equalsAny(v, otherItem) }) < 0 {
+				result = append(result, otherItem)
+			}
+		case ArrayMergeByID:
+			idx := indexByIdentity(result, otherItem, strategy.identityKey)
+			if idx < 0 {
+				result = append(result, otherItem)
+
+				continue
+			}
+
+			itemPath := path.Index(idx)
+
+			merged, err := mergeValues(itemPath, result[idx], otherItem, itemStrategy, options, report)
+			if err != nil {
+				return nil, err
+			}
+
+			result[idx] = merged
+		default:
+			result = append(result, otherItem)
+		}
+	}
+
+	return newListFromValues(result), nil
+}
+
+// indexByIdentity returns the index of the map in items with the same identity field value as item,
+// or -1 if item is not a map with the identity field or there is no match.
+func indexByIdentity(items []any, item any, identityKey string) int {
+	itemMap, ok := item.(*Map)
+	if !ok {
+		return -1
+	}
+
+	id, ok := itemMap.Get(identityKey)
+	if !ok {
+		return -1
+	}
+
+	return goSlices.IndexFunc(items, func(candidate any) bool {
+		candidateMap, isMap := candidate.(*Map)
+		if !isMap {
+			return false
+		}
+
+		candidateID, exists := candidateMap.Get(identityKey)
+
+		return exists && equalsAny(candidateID, id)
+	})
+}
+
+// jsonKind returns the name of the JSON type of a value.
+func jsonKind(v any) string {
+	switch toLargestType(v).(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case int64, uint64, float64:
+		return "number"
+	case *Map:
+		return "object"
+	case *List, []any:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
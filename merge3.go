@@ -0,0 +1,145 @@
+package jsonchamp
+
+import (
+	goSlices "slices"
+)
+
+// Conflict describes a path that was changed differently in both sides of a three-way merge.
+type Conflict struct {
+	// Path is the path of the conflicting value.
+	Path Path
+	// Base, Ours and Theirs are the values at the path in each version.
+	Base   any
+	Ours   any
+	Theirs any
+	// InBase, InOurs and InTheirs report whether the path exists in each version.
+	// A missing path means the key was never set or was deleted.
+	InBase   bool
+	InOurs   bool
+	InTheirs bool
+}
+
+// Resolution is the outcome of resolving a conflict.
+type Resolution struct {
+	// Value is the value to store at the conflicting path.
+	Value any
+	// Delete removes the key at the conflicting path instead of storing Value.
+	Delete bool
+}
+
+// ConflictResolver resolves a conflict in a three-way merge.
+// It returns false if the conflict could not be resolved, in which case it is reported by Merge3.
+type ConflictResolver func(c Conflict) (Resolution, bool)
+
+type merge3Options struct {
+	resolver ConflictResolver
+}
+
+// Merge3Option is a function that sets an option on a three-way merge.
+type Merge3Option func(*merge3Options)
+
+// WithConflictResolver sets the function used to resolve conflicts in a three-way merge.
+func WithConflictResolver(r ConflictResolver) Merge3Option {
+	return func(o *merge3Options) {
+		o.resolver = r
+	}
+}
+
+// Merge3 merges the changes made in ours and theirs, both derived from base.
+// Changes made on only one side are applied automatically, and maps changed on both sides are merged recursively.
+// Any other path changed differently on both sides is a conflict. Conflicts are passed to the resolver if
+// one is set, and the unresolved ones keep the value from ours and are returned in the conflict list.
+func Merge3(base *Map, ours *Map, theirs *Map, opts ...Merge3Option) (*Map, []Conflict) {
+	var options merge3Options
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return merge3(nil, base, ours, theirs, options)
+}
+
+func merge3(path Path, base *Map, ours *Map, theirs *Map, options merge3Options) (*Map, []Conflict) {
+	result := ours
+
+	var conflicts []Conflict
+
+	keys := union(union(base.Keys(), ours.Keys()), theirs.Keys())
+	goSlices.Sort(keys)
+
+	for _, k := range keys {
+		baseValue, inBase := base.Get(k)
+		oursValue, inOurs := ours.Get(k)
+		theirsValue, inTheirs := theirs.Get(k)
+
+		switch {
+		// Both sides agree, or only ours changed.
+		case sameValue(oursValue, inOurs, theirsValue, inTheirs),
+			sameValue(baseValue, inBase, theirsValue, inTheirs):
+			continue
+		// Only theirs changed.
+		case sameValue(baseValue, inBase, oursValue, inOurs):
+			result = setOrDelete(result, k, theirsValue, inTheirs)
+
+			continue
+		}
+
+		keyPath := path.Child(k)
+
+		oursMap, oursIsMap := oursValue.(*Map)
+		theirsMap, theirsIsMap := theirsValue.(*Map)
+
+		if oursIsMap && theirsIsMap {
+			baseMap, baseIsMap := baseValue.(*Map)
+			if !baseIsMap {
+				baseMap = New()
+			}
+
+			merged, subConflicts := merge3(keyPath, baseMap, oursMap, theirsMap, options)
+			result = result.Set(k, merged)
+			conflicts = append(conflicts, subConflicts...)
+
+			continue
+		}
+
+		conflict := Conflict{
+			Path:     keyPath,
+			Base:     baseValue,
+			Ours:     oursValue,
+			Theirs:   theirsValue,
+			InBase:   inBase,
+			InOurs:   inOurs,
+			InTheirs: inTheirs,
+		}
+
+		if options.resolver != nil {
+			if resolution, ok := options.resolver(conflict); ok {
+				result = setOrDelete(result, k, resolution.Value, !resolution.Delete)
+
+				continue
+			}
+		}
+
+		conflicts = append(conflicts, conflict)
+	}
+
+	return result, conflicts
+}
+
+// sameValue returns true if two optional values are both missing or both present and equal.
+func sameValue(a any, aExists bool, b any, bExists bool) bool {
+	if aExists != bExists {
+		return false
+	}
+
+	return !aExists || equalsAny(a, b)
+}
+
+func setOrDelete(m *Map, key string, value any, exists bool) *Map {
+	if !exists {
+		m, _ = m.Delete(key)
+
+		return m
+	}
+
+	return m.Set(key, value)
+}
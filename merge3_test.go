@@ -0,0 +1,147 @@
+package jsonchamp
+
+import (
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		base          string
+		ours          string
+		theirs        string
+		want          string
+		wantConflicts []string
+	}{
+		{
+			name:   "no changes",
+			base:   `{"a":1}`,
+			ours:   `{"a":1}`,
+			theirs: `{"a":1}`,
+			want:   `{"a":1}`,
+		},
+		{
+			name:   "disjoint changes",
+			base:   `{"a":1,"b":1}`,
+			ours:   `{"a":2,"b":1}`,
+			theirs: `{"a":1,"b":2,"c":3}`,
+			want:   `{"a":2,"b":2,"c":3}`,
+		},
+		{
+			name:   "same change on both sides",
+			base:   `{"a":1}`,
+			ours:   `{"a":2}`,
+			theirs: `{"a":2}`,
+			want:   `{"a":2}`,
+		},
+		{
+			name:   "deletion on one side",
+			base:   `{"a":1,"b":1}`,
+			ours:   `{"a":1,"b":1}`,
+			theirs: `{"a":1}`,
+			want:   `{"a":1}`,
+		},
+		{
+			name:   "nested disjoint changes",
+			base:   `{"db":{"host":"a","port":1}}`,
+			ours:   `{"db":{"host":"b","port":1}}`,
+			theirs: `{"db":{"host":"a","port":2}}`,
+			want:   `{"db":{"host":"b","port":2}}`,
+		},
+		{
+			name:          "conflicting scalar keeps ours",
+			base:          `{"a":1}`,
+			ours:          `{"a":2}`,
+			theirs:        `{"a":3}`,
+			want:          `{"a":2}`,
+			wantConflicts: []string{"a"},
+		},
+		{
+			name:          "nested conflict is reported with its path",
+			base:          `{"db":{"host":"a","port":1}}`,
+			ours:          `{"db":{"host":"b","port":1}}`,
+			theirs:        `{"db":{"host":"c","port":2}}`,
+			want:          `{"db":{"host":"b","port":2}}`,
+			wantConflicts: []string{"db.host"},
+		},
+		{
+			name:          "modify and delete conflict",
+			base:          `{"a":1}`,
+			ours:          `{"a":2}`,
+			theirs:        `{}`,
+			want:          `{"a":2}`,
+			wantConflicts: []string{"a"},
+		},
+		{
+			name:   "maps added on both sides are merged",
+			base:   `{}`,
+			ours:   `{"a":{"b":1}}`,
+			theirs: `{"a":{"c":1}}`,
+			want:   `{"a":{"b":1,"c":1}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, conflicts := Merge3(mustParse(t, tt.base), mustParse(t, tt.ours), mustParse(t, tt.theirs))
+			if want := mustParse(t, tt.want); !got.Equals(want) {
+				t.Errorf("Merge3() = %v, want %v", got, want)
+			}
+
+			if len(conflicts) != len(tt.wantConflicts) {
+				t.Fatalf("expected %d conflicts, got %v", len(tt.wantConflicts), conflicts)
+			}
+
+			for i, c := range conflicts {
+				if !c.Path.Equal(MustParsePath(tt.wantConflicts[i])) {
+					t.Errorf("conflict %d: expected path %s, got %s", i, tt.wantConflicts[i], c.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestMerge3ConflictValues(t *testing.T) {
+	t.Parallel()
+
+	_, conflicts := Merge3(mustParse(t, `{"a":1}`), mustParse(t, `{"a":2}`), mustParse(t, `{}`))
+	if len(conflicts) != 1 {
+		t.Fatalf("expected 1 conflict, got %v", conflicts)
+	}
+
+	c := conflicts[0]
+	if !equalsAny(c.Base, 1) || !equalsAny(c.Ours, 2) || c.Theirs != nil {
+		t.Errorf("unexpected conflict values: %+v", c)
+	}
+
+	if !c.InBase || !c.InOurs || c.InTheirs {
+		t.Errorf("unexpected conflict existence: %+v", c)
+	}
+}
+
+func TestMerge3WithConflictResolver(t *testing.T) {
+	t.Parallel()
+
+	preferTheirs := func(c Conflict) (Resolution, bool) {
+		return Resolution{Value: c.Theirs, Delete: !c.InTheirs}, true
+	}
+
+	got, conflicts := Merge3(
+		mustParse(t, `{"a":1,"b":1,"c":1}`),
+		mustParse(t, `{"a":2,"b":2,"c":1}`),
+		mustParse(t, `{"a":3,"c":2}`),
+		WithConflictResolver(preferTheirs),
+	)
+
+	if len(conflicts) != 0 {
+		t.Errorf("expected all conflicts to be resolved, got %v", conflicts)
+	}
+
+	if want := mustParse(t, `{"a":3,"c":2}`); !got.Equals(want) {
+		t.Errorf("Merge3() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,135 @@
+package jsonchamp
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestMergeWith(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		current         string
+		other           string
+		opts            []MergeOption
+		want            string
+		wantOverwritten []Path
+	}{
+		{
+			name:            "default replaces arrays",
+			current:         `{"a":[1,2],"b":{"c":1}}`,
+			other:           `{"a":[3],"b":{"d":2}}`,
+			want:            `{"a":[3],"b":{"c":1,"d":2}}`,
+			wantOverwritten: []Path{MustParsePath("a")},
+		},
+		{
+			name:    "append arrays",
+			current: `{"a":[1,2]}`,
+			other:   `{"a":[2,3]}`,
+			opts:    []MergeOption{WithArrayStrategy(ArrayAppend)},
+			want:    `{"a":[1,2,2,3]}`,
+		},
+		{
+			name:    "union arrays",
+			current: `{"a":[1,2]}`,
+			other:   `{"a":[2,3]}`,
+			opts:    []MergeOption{WithArrayStrategy(ArrayUnion)},
+			want:    `{"a":[1,2,3]}`,
+		},
+		{
+			name:            "merge arrays by identity",
+			current:         `{"items":[{"id":1,"name":"a","tags":["x"]},{"id":2,"name":"b"}]}`,
+			other:           `{"items":[{"id":2,"name":"c"},{"id":3,"name":"d"}]}`,
+			opts:            []MergeOption{WithArrayIdentity("id")},
+			want:            `{"items":[{"id":1,"name":"a","tags":["x"]},{"id":2,"name":"c"},{"id":3,"name":"d"}]}`,
+			wantOverwritten: []Path{MustParsePath("items.1.name")},
+		},
+		{
+			name:            "nil is set by default",
+			current:         `{"a":1}`,
+			other:           `{"a":null}`,
+			want:            `{"a":null}`,
+			wantOverwritten: []Path{MustParsePath("a")},
+		},
+		{
+			name:            "nil deletes",
+			current:         `{"a":1,"b":{"c":1,"d":2}}`,
+			other:           `{"a":null,"b":{"c":null},"e":null}`,
+			opts:            []MergeOption{WithNullStrategy(NullDelete)},
+			want:            `{"b":{"d":2}}`,
+			wantOverwritten: []Path{MustParsePath("a"), MustParsePath("b.c")},
+		},
+		{
+			name:    "nil deletes in added maps",
+			current: `{"a":1}`,
+			other:   `{"b":{"c":null,"d":{"e":null,"f":2}},"g":{"h":null}}`,
+			opts: []MergeOption{
+				WithNullStrategy(NullDelete),
+				AtPath(MustParsePath("g"), WithNullStrategy(NullSet)),
+			},
+			want:            `{"a":1,"b":{"d":{"f":2}},"g":{"h":null}}`,
+			wantOverwritten: nil,
+		},
+		{
+			name:    "path strategy overrides global strategy",
+			current: `{"a":[1],"b":{"c":[1]}}`,
+			other:   `{"a":[2],"b":{"c":[2]}}`,
+			opts: []MergeOption{
+				WithArrayStrategy(ArrayAppend),
+				AtPath(MustParsePath("b"), WithArrayStrategy(ArrayReplace)),
+			},
+			want:            `{"a":[1,2],"b":{"c":[2]}}`,
+			wantOverwritten: []Path{MustParsePath("b.c")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			current := mustParse(t, tt.current)
+
+			got, report, err := current.MergeWith(mustParse(t, tt.other), tt.opts...)
+			if err != nil {
+				t.Fatalf("MergeWith() error = %v", err)
+			}
+
+			if want := mustParse(t, tt.want); !got.Equals(want) {
+				t.Errorf("MergeWith() = %v, want %v", got, want)
+			}
+
+			if !current.Equals(mustParse(t, tt.current)) {
+				t.Errorf("current map was modified: %v", current)
+			}
+
+			if !slices.EqualFunc(report.Overwritten, tt.wantOverwritten, Path.Equal) {
+				t.Errorf("Overwritten = %v, want %v", report.Overwritten, tt.wantOverwritten)
+			}
+		})
+	}
+}
+
+func TestMergeWithTypeMismatch(t *testing.T) {
+	t.Parallel()
+
+	current := mustParse(t, `{"a":{"b":1}}`)
+
+	_, _, err := current.MergeWith(mustParse(t, `{"a":{"b":"1"}}`), WithTypeMismatchError())
+	if !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("expected ErrTypeMismatch, got %v", err)
+	}
+
+	_, _, err = current.MergeWith(mustParse(t, `{"a":{"b":2.5}}`), WithTypeMismatchError())
+	if err != nil {
+		t.Errorf("expected numbers to be compatible, got %v", err)
+	}
+
+	merged, _, err := mustParse(t, `{"a":null}`).MergeWith(mustParse(t, `{"a":{"b":1}}`), WithTypeMismatchError())
+	if err != nil {
+		t.Errorf("expected null to be replaced like a missing value, got %v", err)
+	} else if want := mustParse(t, `{"a":{"b":1}}`); !merged.Equals(want) {
+		t.Errorf("MergeWith() = %v, want %v", merged, want)
+	}
+}
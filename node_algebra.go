@@ -0,0 +1,407 @@
+package jsonchamp
+
+import (
+	"fmt"
+	"math/bits"
+	"sync/atomic"
+)
+
+// entryResolver picks the entry to keep when both nodes hold the same key.
+type entryResolver func(a *value, b *value) *value
+
+// keepLeft keeps the entry from the first node.
+func keepLeft(a *value, _ *value) *value {
+	return a
+}
+
+// nodeBuilder collects the entries of a new node in ascending bit position order.
+type nodeBuilder struct {
+	level      uint8
+	valueMap   uint64
+	subMapsMap uint64
+	entries    []node
+	size       int
+}
+
+func newNodeBuilder(level uint8) *nodeBuilder {
+	return &nodeBuilder{level: level, valueMap: 0, subMapsMap: 0, entries: nil, size: 0}
+}
+
+func (nb *nodeBuilder) addValue(pos uint64, v *value) {
+	nb.valueMap |= pos
+	nb.entries = append(nb.entries, v)
+	nb.size++
+}
+
+func (nb *nodeBuilder) addSubNode(pos uint64, sub *bitmasked) {
+	if sub.size == 0 {
+		return
+	}
+
+	nb.subMapsMap |= pos
+	nb.entries = append(nb.entries, sub)
+	nb.size += sub.size
+}
+
+func (nb *nodeBuilder) build() *bitmasked {
+	return &bitmasked{
+		level:      nb.level,
+		valueMap:   nb.valueMap,
+		subMapsMap: nb.subMapsMap,
+		values:     newCowSliceWithItems(nb.entries...),
+		size:       nb.size,
+		digest:     atomic.Pointer[digest]{},
+		lt:         atomic.Pointer[ltHash]{},
+		small:      nil,
+	}
+}
+
+// entryAt returns the value or sub node stored at the bit position.
+func (b *bitmasked) entryAt(pos uint64) (*value, *bitmasked) {
+	switch {
+	case b.valueMap&pos != 0:
+		v, ok := b.values.Get(b.index(pos)).(*value)
+		if !ok {
+			panic(fmt.Sprintf("value not correct type: %T", b.values.Get(b.index(pos))))
+		}
+
+		return v, nil
+	case b.subMapsMap&pos != 0:
+		sub, ok := b.values.Get(b.index(pos)).(*bitmasked)
+		if !ok {
+			panic(fmt.Sprintf("subnode not correct type: %T", b.values.Get(b.index(pos))))
+		}
+
+		return nil, sub
+	default:
+		return nil, nil
+	}
+}
+
+// lookup returns the value node for the key.
+func (b *bitmasked) lookup(k key) (*value, bool) {
+	if b.isSmall() {
+		if i := b.smallIndex(k); i >= 0 {
+			return b.small[i], true
+		}
+
+		return nil, false
+	}
+
+	v, sub := b.entryAt(bitPosition(k.hash, b.level))
+
+	switch {
+	case v != nil:
+		return v, v.key == k
+	case sub != nil:
+		return sub.lookup(k)
+	default:
+		return nil, false
+	}
+}
+
+// setValue returns a sub node with the value node added.
+func (b *bitmasked) setValue(v *value) *bitmasked {
+	newNode, ok := b.set(v.key, v.value).(*bitmasked)
+	if !ok {
+		panic("expected bitmasked")
+	}
+
+	return newNode
+}
+
+// all calls yield for every value node in the node and its sub nodes, stopping if yield returns false.
+func (b *bitmasked) all(yield func(v *value) bool) bool {
+	if b.isSmall() {
+		for _, v := range b.small {
+			if !yield(v) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, entry := range b.values.Values() {
+		switch e := entry.(type) {
+		case *value:
+			if !yield(e) {
+				return false
+			}
+		case *bitmasked:
+			if !e.all(yield) {
+				return false
+			}
+		case *collision:
+			for _, v := range e.values {
+				if !yield(v) {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// positions calls fn for every bit position that is used in either node, in ascending order.
+func positions(a *bitmasked, b *bitmasked, fn func(pos uint64)) {
+	used := a.valueMap | a.subMapsMap | b.valueMap | b.subMapsMap
+	for used != 0 {
+		pos := uint64(1) << bits.TrailingZeros64(used)
+		fn(pos)
+		used &^= pos
+	}
+}
+
+// unionNodes returns a node with the keys of both nodes.
+// Sub nodes that only exist in one of the nodes, or are shared by both, are reused as they are.
+func unionNodes(a *bitmasked, b *bitmasked, resolve entryResolver) *bitmasked {
+	if a == b {
+		return a
+	}
+
+	a, b = a.trie(), b.trie()
+
+	nb := newNodeBuilder(a.level)
+
+	positions(a, b, func(pos uint64) {
+		aValue, aSub := a.entryAt(pos)
+		bValue, bSub := b.entryAt(pos)
+
+		switch {
+		case aValue == nil && aSub == nil:
+			nb.addEntry(pos, bValue, bSub)
+		case bValue == nil && bSub == nil:
+			nb.addEntry(pos, aValue, aSub)
+		case aValue != nil && bValue != nil:
+			if aValue.key == bValue.key {
+				nb.addValue(pos, resolve(aValue, bValue))
+
+				return
+			}
+
+			merged, ok := a.mergeValueToSubNode(a.level+1, aValue.key, aValue.value, bValue.key, bValue.value).(*bitmasked)
+			if !ok {
+				panic("expected bitmasked")
+			}
+
+			nb.addSubNode(pos, merged)
+		case aValue != nil:
+			nb.addSubNode(pos, bSub.setValue(resolveAgainst(aValue, bSub, resolve, false)))
+		case bValue != nil:
+			nb.addSubNode(pos, aSub.setValue(resolveAgainst(bValue, aSub, resolve, true)))
+		default:
+			nb.addSubNode(pos, unionNodes(aSub, bSub, resolve))
+		}
+	})
+
+	return nb.build()
+}
+
+// resolveAgainst resolves a value against the value with the same key in a sub node, if there is one.
+// The subNodeIsLeft flag tells which side of the resolver the sub node belongs to.
+func resolveAgainst(v *value, sub *bitmasked, resolve entryResolver, subNodeIsLeft bool) *value {
+	existing, ok := sub.lookup(v.key)
+	if !ok {
+		return v
+	}
+
+	if subNodeIsLeft {
+		return resolve(existing, v)
+	}
+
+	return resolve(v, existing)
+}
+
+func (nb *nodeBuilder) addEntry(pos uint64, v *value, sub *bitmasked) {
+	if v != nil {
+		nb.addValue(pos, v)
+
+		return
+	}
+
+	nb.addSubNode(pos, sub)
+}
+
+// intersectNodes returns a node with the keys that exist in both nodes.
+// Sub nodes that are shared by both nodes are reused as they are.
+func intersectNodes(a *bitmasked, b *bitmasked, resolve entryResolver) *bitmasked {
+	if a == b {
+		return a
+	}
+
+	a, b = a.trie(), b.trie()
+
+	nb := newNodeBuilder(a.level)
+
+	positions(a, b, func(pos uint64) {
+		aValue, aSub := a.entryAt(pos)
+		bValue, bSub := b.entryAt(pos)
+
+		switch {
+		case (aValue == nil && aSub == nil) || (bValue == nil && bSub == nil):
+			return
+		case aValue != nil && bValue != nil:
+			if aValue.key == bValue.key {
+				nb.addValue(pos, resolve(aValue, bValue))
+			}
+		case aValue != nil:
+			if existing, ok := bSub.lookup(aValue.key); ok {
+				nb.addValue(pos, resolve(aValue, existing))
+			}
+		case bValue != nil:
+			if existing, ok := aSub.lookup(bValue.key); ok {
+				nb.addValue(pos, resolve(existing, bValue))
+			}
+		default:
+			nb.addSubNode(pos, intersectNodes(aSub, bSub, resolve))
+		}
+	})
+
+	return nb.build()
+}
+
+// differenceNodes returns a node with the keys of a that do not exist in b.
+// Sub nodes that only exist in a are reused as they are.
+func differenceNodes(a *bitmasked, b *bitmasked) *bitmasked {
+	nb := newNodeBuilder(a.level)
+
+	if a == b {
+		return nb.build()
+	}
+
+	a, b = a.trie(), b.trie()
+
+	positions(a, b, func(pos uint64) {
+		aValue, aSub := a.entryAt(pos)
+		bValue, bSub := b.entryAt(pos)
+
+		switch {
+		case aValue == nil && aSub == nil:
+			return
+		case bValue == nil && bSub == nil:
+			nb.addEntry(pos, aValue, aSub)
+		case aValue != nil && bValue != nil:
+			if aValue.key != bValue.key {
+				nb.addValue(pos, aValue)
+			}
+		case aValue != nil:
+			if _, ok := bSub.lookup(aValue.key); !ok {
+				nb.addValue(pos, aValue)
+			}
+		case bValue != nil:
+			remaining, _ := aSub.delete(bValue.key)
+			nb.addSubNode(pos, remaining)
+		default:
+			nb.addSubNode(pos, differenceNodes(aSub, bSub))
+		}
+	})
+
+	return nb.build()
+}
+
+// diffNodes calls onChange for every key whose value node differs between the nodes.
+// A nil value node means the key does not exist on that side. Sub nodes shared by both nodes are skipped,
+// so comparing two versions of a map only visits the paths that were changed.
+func diffNodes(a *bitmasked, b *bitmasked, onChange func(aValue *value, bValue *value)) {
+	if a == b {
+		return
+	}
+
+	a, b = a.trie(), b.trie()
+
+	positions(a, b, func(pos uint64) {
+		aValue, aSub := a.entryAt(pos)
+		bValue, bSub := b.entryAt(pos)
+
+		switch {
+		case aValue != nil && bValue != nil:
+			if aValue.key == bValue.key {
+				if aValue != bValue {
+					onChange(aValue, bValue)
+				}
+
+				return
+			}
+
+			onChange(aValue, nil)
+			onChange(nil, bValue)
+		case aSub != nil && bSub != nil:
+			diffNodes(aSub, bSub, onChange)
+		case aValue != nil || aSub != nil:
+			diffEntryAgainst(aValue, aSub, bValue, bSub, onChange, false)
+		default:
+			diffEntryAgainst(bValue, bSub, aValue, aSub, onChange, true)
+		}
+	})
+}
+
+// diffEntryAgainst compares a value or sub node on one side with what is at the same position on the other side,
+// which is either nothing, a value or a sub node of a different kind. The swapped flag tells that the first entry
+// belongs to the second node passed to diffNodes.
+func diffEntryAgainst(
+	v *value,
+	sub *bitmasked,
+	otherValue *value,
+	otherSub *bitmasked,
+	onChange func(aValue *value, bValue *value),
+	swapped bool,
+) {
+	emit := func(mine *value, theirs *value) {
+		if swapped {
+			onChange(theirs, mine)
+		} else {
+			onChange(mine, theirs)
+		}
+	}
+
+	switch {
+	case otherValue == nil && otherSub == nil:
+		if v != nil {
+			emit(v, nil)
+
+			return
+		}
+
+		sub.all(func(mine *value) bool {
+			emit(mine, nil)
+
+			return true
+		})
+	case v != nil:
+		// A value against a sub node: the value may exist in the sub node, and all other keys are one-sided.
+		otherSub.all(func(theirs *value) bool {
+			if theirs.key == v.key {
+				if theirs != v {
+					emit(v, theirs)
+				}
+			} else {
+				emit(nil, theirs)
+			}
+
+			return true
+		})
+
+		if _, ok := otherSub.lookup(v.key); !ok {
+			emit(v, nil)
+		}
+	default:
+		// A sub node against a value.
+		sub.all(func(mine *value) bool {
+			if mine.key == otherValue.key {
+				if mine != otherValue {
+					emit(mine, otherValue)
+				}
+			} else {
+				emit(mine, nil)
+			}
+
+			return true
+		})
+
+		if _, ok := sub.lookup(otherValue.key); !ok {
+			emit(nil, otherValue)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package jsonchamp
 import (
 	"fmt"
 	"math/bits"
+	"sync/atomic"
 )
 
 const (
@@ -38,6 +39,20 @@ type bitmasked struct {
 	valueMap   uint64
 	subMapsMap uint64
 	values     *cowSlice
+	// size is the number of keys stored in the node and its sub nodes.
+	size int
+	// digest caches the content digest of the map whose root is this node. It is not used for sub nodes.
+	// Nodes are not modified after they are shared, so the digest stays valid for as long as the node exists.
+	digest atomic.Pointer[digest]
+	// lt caches the LtHash of the entries in the node and its sub nodes, for nodes with at least ltHashMinSize keys.
+	lt atomic.Pointer[ltHash]
+	// small holds the value nodes of a small root node in a flat array, in the order they were set, instead of a trie.
+	// It is nil for trie nodes. See smallMaxSize.
+	small []*value
+}
+
+func newRootNode() *bitmasked {
+	return newSmallNode([]*value{})
 }
 
 func (b *bitmasked) index(pos uint64) int {
@@ -45,6 +60,15 @@ func (b *bitmasked) index(pos uint64) int {
 }
 
 func (b *bitmasked) keys() []string {
+	if b.isSmall() {
+		keys := make([]string, len(b.small))
+		for i, v := range b.small {
+			keys[i] = v.key.key
+		}
+
+		return keys
+	}
+
 	keys := make([]string, 0, b.values.Len())
 
 	for _, v := range b.values.Values() {
@@ -65,6 +89,14 @@ func (b *bitmasked) keys() []string {
 
 // Get implements node.
 func (b *bitmasked) get(key key) (any, bool) {
+	if b.isSmall() {
+		if i := b.smallIndex(key); i >= 0 {
+			return b.small[i].value, true
+		}
+
+		return nil, false
+	}
+
 	pos := bitPosition(key.hash, b.level)
 
 	if b.valueMap&pos != 0 {
@@ -108,6 +140,10 @@ func (b *bitmasked) mergeValueToSubNode(newLevel uint8, keyA key, valueA any, ke
 			values: newCowSliceWithItems(
 				b.mergeValueToSubNode(newLevel+1, keyA, valueA, keyB, valueB),
 			),
+			size:   2,
+			digest: atomic.Pointer[digest]{},
+			lt:     atomic.Pointer[ltHash]{},
+			small:  nil,
 		}
 	}
 
@@ -120,6 +156,10 @@ func (b *bitmasked) mergeValueToSubNode(newLevel uint8, keyA key, valueA any, ke
 				&value{key: keyA, value: valueA},
 				&value{key: keyB, value: valueB},
 			),
+			size:   2,
+			digest: atomic.Pointer[digest]{},
+			lt:     atomic.Pointer[ltHash]{},
+			small:  nil,
 		}
 	}
 
@@ -131,10 +171,18 @@ func (b *bitmasked) mergeValueToSubNode(newLevel uint8, keyA key, valueA any, ke
 			&value{key: keyB, value: valueB},
 			&value{key: keyA, value: valueA},
 		),
+		size:   2,
+		digest: atomic.Pointer[digest]{},
+		lt:     atomic.Pointer[ltHash]{},
+		small:  nil,
 	}
 }
 
 func (b *bitmasked) set(key key, newValue any) node {
+	if b.isSmall() {
+		return b.smallSet(key, newValue)
+	}
+
 	currentSubNode := b
 
 	pos := bitPosition(key.hash, currentSubNode.level)
@@ -162,11 +210,20 @@ func (b *bitmasked) set(key key, newValue any) node {
 			panic(fmt.Sprintf("subnode not correct type: %s, %T", key.key, indexedNode))
 		}
 
+		newSubNode, ok := subNode.set(key, newValue).(*bitmasked)
+		if !ok {
+			panic("expected bitmasked")
+		}
+
 		return &bitmasked{
 			level:      currentSubNode.level,
 			valueMap:   currentSubNode.valueMap,
 			subMapsMap: currentSubNode.subMapsMap,
-			values:     currentSubNode.values.Set(valueIdx, subNode.set(key, newValue)),
+			values:     currentSubNode.values.Set(valueIdx, newSubNode),
+			size:       currentSubNode.size - subNode.size + newSubNode.size,
+			digest:     atomic.Pointer[digest]{},
+			lt:         atomic.Pointer[ltHash]{},
+			small:      nil,
 		}
 
 	// The leaf node exists.
@@ -184,6 +241,10 @@ func (b *bitmasked) set(key key, newValue any) node {
 				valueMap:   currentSubNode.valueMap,
 				subMapsMap: currentSubNode.subMapsMap,
 				values:     currentSubNode.values.Set(valueIdx, &value{key: key, value: newValue}),
+				size:       currentSubNode.size,
+				digest:     atomic.Pointer[digest]{},
+				lt:         atomic.Pointer[ltHash]{},
+				small:      nil,
 			}
 		}
 
@@ -200,6 +261,10 @@ func (b *bitmasked) set(key key, newValue any) node {
 					newValue,
 				),
 			),
+			size:   currentSubNode.size + 1,
+			digest: atomic.Pointer[digest]{},
+			lt:     atomic.Pointer[ltHash]{},
+			small:  nil,
 		}
 
 	// The hash partition does not exist.
@@ -210,22 +275,38 @@ func (b *bitmasked) set(key key, newValue any) node {
 			subMapsMap: currentSubNode.subMapsMap,
 			level:      currentSubNode.level,
 			values:     currentSubNode.values.Insert(valueIdx, &value{key: key, value: newValue}),
+			size:       currentSubNode.size + 1,
+			digest:     atomic.Pointer[digest]{},
+			lt:         atomic.Pointer[ltHash]{},
+			small:      nil,
 		}
 	}
 
 }
 
 func (b *bitmasked) copy() node {
+	if b.isSmall() {
+		return newSmallNode(b.small)
+	}
+
 	return &bitmasked{
 		level:      b.level,
 		valueMap:   b.valueMap,
 		subMapsMap: b.subMapsMap,
-		values:     b.values.Share(),
+		values:     b.values,
+		size:       b.size,
+		digest:     atomic.Pointer[digest]{},
+		lt:         atomic.Pointer[ltHash]{},
+		small:      nil,
 	}
 }
 
 // delete deletes a key from the map. If the key does not exist, it returns false.
 func (b *bitmasked) delete(key key) (*bitmasked, bool) {
+	if b.isSmall() {
+		return b.smallDelete(key)
+	}
+
 	if b.values.Len() == 0 {
 		return b, false
 	}
@@ -236,9 +317,16 @@ func (b *bitmasked) delete(key key) (*bitmasked, bool) {
 	if valueExists {
 		valueIdx := b.index(pos)
 
+		// The partition may hold a different key with the same hash prefix.
+		existing, ok := b.values.Get(valueIdx).(*value)
+		if ok && existing.key != key {
+			return b, false
+		}
+
 		newMap := b.copy().(*bitmasked)
 		newMap.valueMap = b.valueMap ^ pos
 		newMap.values = newMap.values.Delete(valueIdx)
+		newMap.size--
 
 		return newMap, true
 	}
@@ -276,16 +364,18 @@ func (b *bitmasked) delete(key key) (*bitmasked, bool) {
 
 		newMap.subMapsMap = b.subMapsMap ^ pos
 		newMap.values = newMap.values.Delete(subNodeIndex)
+		newMap.size = b.size - subNode.size
 
 		return newMap, ok
 	}
 
-	newB, ok := b.copy().(*bitmasked)
-	if !ok {
+	newB, isBitmasked := b.copy().(*bitmasked)
+	if !isBitmasked {
 		panic("expected bitmasked")
 	}
 
 	newB.values = newB.values.Set(subNodeIndex, subNodeCopy)
+	newB.size = b.size - subNode.size + subNodeCopy.size
 
 	return newB, ok
 }
@@ -295,4 +385,8 @@ var _ node = &bitmasked{
 	valueMap:   0,
 	subMapsMap: 0,
 	values:     nil,
+	size:       0,
+	digest:     atomic.Pointer[digest]{},
+	lt:         atomic.Pointer[ltHash]{},
+	small:      nil,
 }
@@ -0,0 +1,129 @@
+package jsonchamp
+
+import (
+	"cmp"
+	goSlices "slices"
+	"sync/atomic"
+)
+
+// smallMaxSize is the number of keys up to which a root node keeps its value nodes in a flat array.
+// Most JSON objects have only a few keys, and for them scanning an array is faster than walking the trie
+// and needs fewer allocations. A small node that grows beyond it is converted to a trie. Deleting keys from
+// a trie does not convert it back, so maps that shrink and grow around the limit are not converted repeatedly.
+const smallMaxSize = 8
+
+func newSmallNode(values []*value) *bitmasked {
+	return &bitmasked{
+		level:      0,
+		valueMap:   0,
+		subMapsMap: 0,
+		values:     nil,
+		size:       len(values),
+		digest:     atomic.Pointer[digest]{},
+		lt:         atomic.Pointer[ltHash]{},
+		small:      values,
+	}
+}
+
+func (b *bitmasked) isSmall() bool {
+	return b.small != nil
+}
+
+// smallIndex returns the index of the key in a small node, or -1 if it does not exist.
+func (b *bitmasked) smallIndex(k key) int {
+	for i, v := range b.small {
+		if v.key == k {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// smallGet returns the value of a key in a small node. It compares the names of the keys, so the key
+// does not need to be hashed.
+func (b *bitmasked) smallGet(name string) (any, bool) {
+	for _, v := range b.small {
+		if v.key.key == name {
+			return v.value, true
+		}
+	}
+
+	return nil, false
+}
+
+func (b *bitmasked) smallSet(k key, newValue any) *bitmasked {
+	entry := &value{key: k, value: newValue}
+
+	if i := b.smallIndex(k); i >= 0 {
+		values := goSlices.Clone(b.small)
+		values[i] = entry
+
+		return newSmallNode(values)
+	}
+
+	values := make([]*value, len(b.small), len(b.small)+1)
+	copy(values, b.small)
+	values = append(values, entry)
+
+	if len(values) > smallMaxSize {
+		return trieOf(0, values)
+	}
+
+	return newSmallNode(values)
+}
+
+func (b *bitmasked) smallDelete(k key) (*bitmasked, bool) {
+	i := b.smallIndex(k)
+	if i < 0 {
+		return b, false
+	}
+
+	values := make([]*value, 0, len(b.small)-1)
+	values = append(values, b.small[:i]...)
+	values = append(values, b.small[i+1:]...)
+
+	return newSmallNode(values), true
+}
+
+// trie returns the node as a trie, converting a small node. The value nodes are reused, so tries built
+// from versions of a small node can still be compared node by node.
+func (b *bitmasked) trie() *bitmasked {
+	if !b.isSmall() {
+		return b
+	}
+
+	return trieOf(0, b.small)
+}
+
+// trieOf builds a trie node at the level from value nodes whose hashes are equal in the partitions above it.
+func trieOf(level uint8, values []*value) *bitmasked {
+	if level > maxTreeDepth {
+		panic("Max level reached")
+	}
+
+	sorted := goSlices.Clone(values)
+	goSlices.SortFunc(sorted, func(a *value, b *value) int {
+		return cmp.Compare(partition(a.key.hash, level), partition(b.key.hash, level))
+	})
+
+	nb := newNodeBuilder(level)
+
+	for start := 0; start < len(sorted); {
+		end := start + 1
+		for end < len(sorted) && partition(sorted[end].key.hash, level) == partition(sorted[start].key.hash, level) {
+			end++
+		}
+
+		pos := bitPosition(sorted[start].key.hash, level)
+		if end-start == 1 {
+			nb.addValue(pos, sorted[start])
+		} else {
+			nb.addSubNode(pos, trieOf(level+1, sorted[start:end]))
+		}
+
+		start = end
+	}
+
+	return nb.build()
+}
@@ -0,0 +1,109 @@
+package jsonchamp
+
+import (
+	"fmt"
+	"hash/fnv"
+	"testing"
+)
+
+func TestSmallNodeUpgradesToTrie(t *testing.T) {
+	t.Parallel()
+
+	for _, opts := range [][]MapOption{nil, {WithHasher(fnv.New64a)}} {
+		m := New(opts...)
+
+		for i := range smallMaxSize * 4 {
+			m = m.Set(fmt.Sprintf("key%d", i), i)
+
+			if small := m.Len() <= smallMaxSize; m.root.isSmall() != small {
+				t.Fatalf("isSmall() = %v with %d keys, want %v", m.root.isSmall(), m.Len(), small)
+			}
+
+			for j := range i + 1 {
+				if v, ok := m.Get(fmt.Sprintf("key%d", j)); !ok || v != int64(j) {
+					t.Fatalf("Get(key%d) = %v, %v, want %d, true", j, v, ok, j)
+				}
+			}
+		}
+
+		for i := range smallMaxSize * 4 {
+			var ok bool
+
+			m, ok = m.Delete(fmt.Sprintf("key%d", i))
+			if !ok {
+				t.Fatalf("Delete(key%d) = false, want true", i)
+			}
+		}
+
+		if m.Len() != 0 || len(m.Keys()) != 0 {
+			t.Errorf("Len() = %d after deleting all keys, want 0", m.Len())
+		}
+	}
+}
+
+func TestSmallNodeSetAndDelete(t *testing.T) {
+	t.Parallel()
+
+	m := NewFromItems("a", 1, "b", 2, "c", 3)
+
+	updated := m.Set("b", 20)
+	if v, _ := m.Get("b"); v != int64(2) {
+		t.Errorf("Set() changed the original map: b = %v", v)
+	}
+
+	if v, _ := updated.Get("b"); v != int64(20) || updated.Len() != 3 {
+		t.Errorf("Set() = %v with %d keys, want b = 20 with 3 keys", v, updated.Len())
+	}
+
+	deleted, ok := updated.Delete("a")
+	if !ok || deleted.Len() != 2 || deleted.Contains("a") || !updated.Contains("a") {
+		t.Errorf("Delete(a) = %v, %v", deleted, ok)
+	}
+
+	if _, ok := deleted.Delete("missing"); ok {
+		t.Errorf("Delete(missing) = true, want false")
+	}
+}
+
+func TestSmallNodeAgainstTrie(t *testing.T) {
+	t.Parallel()
+
+	small := NewFromItems("a", 1, "b", 2, "c", 3)
+
+	large := small
+	for i := range smallMaxSize {
+		large = large.Set(fmt.Sprintf("key%d", i), i)
+	}
+
+	if !large.Intersect(small).Equals(small) {
+		t.Errorf("Intersect() = %v, want %v", large.Intersect(small), small)
+	}
+
+	if got := large.Subtract(small); got.Len() != smallMaxSize || got.Contains("a") {
+		t.Errorf("Subtract() = %v", got)
+	}
+
+	if got := small.SymmetricDifference(large); got.Len() != smallMaxSize {
+		t.Errorf("SymmetricDifference() has %d keys, want %d", got.Len(), smallMaxSize)
+	}
+
+	if got := small.Merge(large); !got.Equals(large) {
+		t.Errorf("Merge() = %v, want %v", got, large)
+	}
+
+	if small.ContentHash() != large.Pick("a", "b", "c").ContentHash() {
+		t.Errorf("ContentHash() of a small map differs from the same keys picked from a trie")
+	}
+}
+
+func TestSmallNodeTypedDiff(t *testing.T) {
+	t.Parallel()
+
+	a := NewTyped[int]().Set("a", 1).Set("b", 2).Set("c", 3)
+	b, _ := a.Set("b", 20).Delete("c")
+
+	changes := a.Diff(b, nil)
+	if len(changes) != 2 || changes[0].Key != "b" || changes[1].Key != "c" {
+		t.Errorf("Diff() = %+v, want b modified and c removed", changes)
+	}
+}
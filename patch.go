@@ -0,0 +1,84 @@
+package jsonchamp
+
+// ApplyMergePatch applies a JSON Merge Patch (RFC 7396) to target and returns the patched map.
+// A nil value in the patch removes the key from the target.
+// A map value in the patch is applied recursively. Any other value replaces the value in the target.
+// The target is left unchanged.
+func ApplyMergePatch(target *Map, patch *Map) *Map {
+	if target == nil {
+		target = New()
+	}
+
+	result := target
+
+	for _, k := range patch.Keys() {
+		patchValue, _ := patch.Get(k)
+
+		if patchValue == nil {
+			result, _ = result.Delete(k)
+
+			continue
+		}
+
+		currentValue, _ := result.Get(k)
+		result = result.Set(k, mergePatchValue(currentValue, patchValue))
+	}
+
+	return result
+}
+
+// mergePatchValue implements the recursive part of the RFC 7396 MergePatch function for a single value.
+func mergePatchValue(target any, patch any) any {
+	patchMap, ok := patch.(*Map)
+	if !ok {
+		return patch
+	}
+
+	targetMap, ok := target.(*Map)
+	if !ok {
+		// Patching a non-object starts from an empty object, which also strips nulls from the patch.
+		targetMap = New()
+	}
+
+	return ApplyMergePatch(targetMap, patchMap)
+}
+
+// CreateMergePatch returns a JSON Merge Patch (RFC 7396) that transforms a into b.
+// Keys that are removed in b are set to nil, changed values are set to the value in b,
+// and nested maps are compared recursively.
+//
+// ApplyMergePatch(a, CreateMergePatch(a, b)) equals b as long as b does not contain nil values,
+// since a merge patch cannot express setting a key to null.
+func CreateMergePatch(a *Map, b *Map) *Map {
+	patch := New()
+
+	for _, k := range union(a.Keys(), b.Keys()) {
+		aValue, aExists := a.Get(k)
+		bValue, bExists := b.Get(k)
+
+		switch {
+		case !bExists:
+			patch = patch.Set(k, nil)
+		case !aExists:
+			patch = patch.Set(k, bValue)
+		default:
+			aMap, aIsMap := aValue.(*Map)
+			bMap, bIsMap := bValue.(*Map)
+
+			if aIsMap && bIsMap {
+				subPatch := CreateMergePatch(aMap, bMap)
+				if len(subPatch.Keys()) > 0 {
+					patch = patch.Set(k, subPatch)
+				}
+
+				continue
+			}
+
+			if !equalsAny(aValue, bValue) {
+				patch = patch.Set(k, bValue)
+			}
+		}
+	}
+
+	return patch
+}
@@ -0,0 +1,110 @@
+package jsonchamp
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func mustParse(t *testing.T, raw string) *Map {
+	t.Helper()
+
+	m := New()
+	if err := json.Unmarshal([]byte(raw), &m); err != nil {
+		t.Fatalf("could not parse %s: %v", raw, err)
+	}
+
+	return m
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	t.Parallel()
+
+	// Test cases from RFC 7396, Appendix A, limited to object targets and patches.
+	tests := []struct {
+		name   string
+		target string
+		patch  string
+		want   string
+	}{
+		{name: "replace value", target: `{"a":"b"}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{name: "add value", target: `{"a":"b"}`, patch: `{"b":"c"}`, want: `{"a":"b","b":"c"}`},
+		{name: "remove only value", target: `{"a":"b"}`, patch: `{"a":null}`, want: `{}`},
+		{name: "remove one value", target: `{"a":"b","b":"c"}`, patch: `{"a":null}`, want: `{"b":"c"}`},
+		{name: "replace array with string", target: `{"a":["b"]}`, patch: `{"a":"c"}`, want: `{"a":"c"}`},
+		{name: "replace string with array", target: `{"a":"c"}`, patch: `{"a":["b"]}`, want: `{"a":["b"]}`},
+		{
+			name:   "nested patch",
+			target: `{"a":{"b":"c"}}`,
+			patch:  `{"a":{"b":"d","c":null}}`,
+			want:   `{"a":{"b":"d"}}`,
+		},
+		{name: "arrays are replaced", target: `{"a":[{"b":"c"}]}`, patch: `{"a":[1]}`, want: `{"a":[1]}`},
+		{name: "existing null is kept", target: `{"e":null}`, patch: `{"a":1}`, want: `{"e":null,"a":1}`},
+		{
+			name:   "nulls in new objects are stripped",
+			target: `{}`,
+			patch:  `{"a":{"bb":{"ccc":null}}}`,
+			want:   `{"a":{"bb":{}}}`,
+		},
+		{name: "removing a missing key", target: `{"a":1}`, patch: `{"b":null}`, want: `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			target := mustParse(t, tt.target)
+			targetBefore := mustParse(t, tt.target)
+
+			got := ApplyMergePatch(target, mustParse(t, tt.patch))
+			if want := mustParse(t, tt.want); !got.Equals(want) {
+				t.Errorf("ApplyMergePatch() = %v, want %v", got, want)
+			}
+
+			if !target.Equals(targetBefore) {
+				t.Errorf("target was modified: %v, want %v", target, targetBefore)
+			}
+		})
+	}
+}
+
+func TestCreateMergePatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		a         string
+		b         string
+		wantPatch string
+	}{
+		{name: "equal documents", a: `{"a":1}`, b: `{"a":1}`, wantPatch: `{}`},
+		{name: "changed value", a: `{"a":1}`, b: `{"a":2}`, wantPatch: `{"a":2}`},
+		{name: "added key", a: `{"a":1}`, b: `{"a":1,"b":true}`, wantPatch: `{"b":true}`},
+		{name: "removed key", a: `{"a":1,"b":2}`, b: `{"a":1}`, wantPatch: `{"b":null}`},
+		{
+			name:      "nested change",
+			a:         `{"a":{"b":1,"c":2,"d":[1,2]}}`,
+			b:         `{"a":{"b":1,"c":3,"e":"x"}}`,
+			wantPatch: `{"a":{"c":3,"d":null,"e":"x"}}`,
+		},
+		{name: "map replaced by scalar", a: `{"a":{"b":1}}`, b: `{"a":1}`, wantPatch: `{"a":1}`},
+		{name: "array change", a: `{"a":[1,2]}`, b: `{"a":[1,3]}`, wantPatch: `{"a":[1,3]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a, b := mustParse(t, tt.a), mustParse(t, tt.b)
+
+			patch := CreateMergePatch(a, b)
+			if want := mustParse(t, tt.wantPatch); !patch.Equals(want) {
+				t.Errorf("CreateMergePatch() = %v, want %v", patch, want)
+			}
+
+			if got := ApplyMergePatch(a, patch); !got.Equals(b) {
+				t.Errorf("ApplyMergePatch(a, CreateMergePatch(a, b)) = %v, want %v", got, b)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package jsonchamp
+
+import (
+	"cmp"
+	"fmt"
+	goSlices "slices"
+	"strconv"
+	"strings"
+)
+
+// PathSegment is a single step in a Path: either an object key or an array index.
+type PathSegment struct {
+	Key     string
+	Index   int
+	IsIndex bool
+}
+
+// KeySegment returns a path segment for an object key.
+func KeySegment(key string) PathSegment {
+	return PathSegment{Key: key, Index: 0, IsIndex: false}
+}
+
+// IndexSegment returns a path segment for an array index.
+func IndexSegment(index int) PathSegment {
+	return PathSegment{Key: "", Index: index, IsIndex: true}
+}
+
+// String returns the key, or the index in decimal.
+func (s PathSegment) String() string {
+	if s.IsIndex {
+		return strconv.Itoa(s.Index)
+	}
+
+	return s.Key
+}
+
+// Compare orders segments with indices before keys, indices by value and keys lexically.
+func (s PathSegment) Compare(other PathSegment) int {
+	switch {
+	case s.IsIndex && other.IsIndex:
+		return cmp.Compare(s.Index, other.Index)
+	case s.IsIndex:
+		return -1
+	case other.IsIndex:
+		return 1
+	default:
+		return strings.Compare(s.Key, other.Key)
+	}
+}
+
+// PathSyntax is a notation for writing a Path as a string.
+type PathSyntax int
+
+const (
+	// PathDots separates all segments with dots, like a.0.b.
+	PathDots PathSyntax = iota
+	// PathBrackets writes array indices in brackets, like a[0].b.
+	PathBrackets
+	// PathPointer writes the path as a JSON Pointer (RFC 6901), like /a/0/b.
+	PathPointer
+)
+
+// Path is the location of a value in a map, from the root map down through nested maps and arrays.
+type Path []PathSegment
+
+// ParsePath parses a path in any of the syntaxes of PathSyntax.
+// Strings that are empty or start with a slash are read as JSON Pointers, so the empty string is the empty path.
+// Other strings are read as keys separated by dots, where segments of digits and numbers in brackets are
+// array indices, like a.0.b or a[0].b. A backslash escapes the next character, so a\.b is the key "a.b"
+// and \0 is the key "0".
+func ParsePath(s string) (Path, error) {
+	if s == "" || s[0] == '/' {
+		return parsePointer(s)
+	}
+
+	return pathOptions(IndexDots).parse(s)
+}
+
+// MustParsePath is like ParsePath but panics if the path cannot be parsed.
+func MustParsePath(s string) Path {
+	path, err := ParsePath(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return path
+}
+
+// pathOptions returns the flatten options for the dot and bracket syntax of a Path.
+func pathOptions(indices IndexNotation) flattenOptions {
+	return flattenOptions{separator: ".", indices: indices, anyIndices: true, err: nil}
+}
+
+func parsePointer(s string) (Path, error) {
+	if s == "" {
+		return Path{}, nil
+	}
+
+	tokens := strings.Split(s[1:], "/")
+	path := make(Path, 0, len(tokens))
+
+	for _, token := range tokens {
+		if index, ok := parseIndex(token); ok {
+			path = append(path, IndexSegment(index))
+
+			continue
+		}
+
+		for i := 0; i < len(token); i++ {
+			if token[i] == '~' && (i+1 >= len(token) || (token[i+1] != '0' && token[i+1] != '1')) {
+				return nil, fmt.Errorf("%w: invalid escape in JSON Pointer '%s'", ErrInvalidPath, s)
+			}
+		}
+
+		path = append(path, KeySegment(strings.NewReplacer("~1", "/", "~0", "~").Replace(token)))
+	}
+
+	return path, nil
+}
+
+// Child returns a new path with the object key added.
+func (p Path) Child(key string) Path {
+	return p.append(KeySegment(key))
+}
+
+// Index returns a new path with the array index added.
+func (p Path) Index(index int) Path {
+	return p.append(IndexSegment(index))
+}
+
+// append always copies, so paths handed out to callbacks never share their backing array.
+func (p Path) append(segment PathSegment) Path {
+	newPath := make(Path, len(p), len(p)+1)
+	copy(newPath, p)
+
+	return append(newPath, segment)
+}
+
+// Parent returns the path without its last segment. The parent of the empty path is the empty path.
+func (p Path) Parent() Path {
+	if len(p) == 0 {
+		return p
+	}
+
+	return p[: len(p)-1 : len(p)-1]
+}
+
+// HasPrefix returns true if the path starts with all segments of prefix.
+func (p Path) HasPrefix(prefix Path) bool {
+	return len(prefix) <= len(p) && p[:len(prefix)].Equal(prefix)
+}
+
+// Equal returns true if both paths have the same segments.
+func (p Path) Equal(other Path) bool {
+	return goSlices.Equal(p, other)
+}
+
+// Compare orders paths segment by segment, with a path before the paths it is a prefix of.
+func (p Path) Compare(other Path) int {
+	return goSlices.CompareFunc(p, other, PathSegment.Compare)
+}
+
+// String returns the path in the PathDots syntax.
+func (p Path) String() string {
+	return p.Format(PathDots)
+}
+
+// Format returns the path in the given syntax. The result can be read back with ParsePath.
+// The empty string is the empty path, so a path of only the empty key is written as the JSON Pointer "/"
+// in every syntax.
+func (p Path) Format(syntax PathSyntax) string {
+	if len(p) == 1 && p[0] == KeySegment("") {
+		return "/"
+	}
+
+	switch syntax {
+	case PathBrackets:
+		return pathOptions(IndexBrackets).format(p)
+	case PathPointer:
+		var b strings.Builder
+		for _, segment := range p {
+			b.WriteString("/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(segment.String()))
+		}
+
+		return b.String()
+	default:
+		return pathOptions(IndexDots).format(p)
+	}
+}
+
+// getPath follows the path from a value through nested maps and lists.
+func getPath(v any, path Path) (any, bool) {
+	for _, segment := range path {
+		var ok bool
+		if v, ok = childAt(v, segment); !ok {
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+// childAt returns the value of a map or list at the segment.
+// Index segments can address keys of digits in maps, and key segments of digits can address elements of lists,
+// since JSON Pointers cannot tell them apart.
+func childAt(v any, segment PathSegment) (any, bool) {
+	switch t := v.(type) {
+	case *Map:
+		return t.Get(segment.String())
+	case *List:
+		if segment.IsIndex {
+			return t.Get(segment.Index)
+		}
+
+		index, ok := parseIndex(segment.Key)
+		if !ok {
+			return nil, false
+		}
+
+		return t.Get(index)
+	default:
+		return nil, false
+	}
+}
+
+// setPath returns the value with the value at the path replaced, creating missing maps on the way.
+// It returns false if the path goes through a value that is not a map or array, or an index that does not exist.
+func setPath(v any, path Path, value any) (any, bool) {
+	if len(path) == 0 {
+		return value, true
+	}
+
+	segment := path[0]
+
+	switch t := v.(type) {
+	case *Map:
+		key := segment.String()
+
+		child, ok := t.Get(key)
+		if !ok && len(path) > 1 {
+			// Missing maps are created with the options of their parent.
+			child = &Map{root: newRootNode(), hasher: t.hasher, interner: t.interner}
+		}
+
+		updated, ok := setPath(child, path[1:], value)
+		if !ok {
+			return nil, false
+		}
+
+		return t.Set(key, updated), true
+	case *List:
+		index, ok := segment.Index, segment.IsIndex
+		if !ok {
+			index, ok = parseIndex(segment.Key)
+		}
+
+		child, exists := t.Get(index)
+		if !ok || !exists {
+			return nil, false
+		}
+
+		updated, ok := setPath(child, path[1:], value)
+		if !ok {
+			return nil, false
+		}
+
+		return t.Set(index, updated), true
+	default:
+		return nil, false
+	}
+}
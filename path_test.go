@@ -0,0 +1,180 @@
+package jsonchamp
+
+import (
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestParsePath(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want Path
+	}{
+		{name: "dots", in: "a.0.b", want: Path{KeySegment("a"), IndexSegment(0), KeySegment("b")}},
+		{name: "brackets", in: "a[0][12].b", want: Path{KeySegment("a"), IndexSegment(0), IndexSegment(12), KeySegment("b")}},
+		{name: "escaped", in: `a\.b.\0.c\[d\]`, want: Path{KeySegment("a.b"), KeySegment("0"), KeySegment("c[d]")}},
+		{name: "leading zero is a key", in: "a.01", want: Path{KeySegment("a"), KeySegment("01")}},
+		{name: "pointer", in: "/a/0/b~1c~0d", want: Path{KeySegment("a"), IndexSegment(0), KeySegment("b/c~d")}},
+		{name: "pointer empty key", in: "/", want: Path{KeySegment("")}},
+		{name: "empty", in: "", want: Path{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParsePath(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !got.Equal(tt.want) {
+				t.Errorf("ParsePath(%q) = %#v, want %#v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{"a[x]", "a[0", "a[0]b", `a\`, "/a~2"} {
+		if _, err := ParsePath(in); !errors.Is(err, ErrInvalidPath) {
+			t.Errorf("ParsePath(%q) error = %v, want %v", in, err, ErrInvalidPath)
+		}
+	}
+}
+
+func TestPathFormat(t *testing.T) {
+	t.Parallel()
+
+	path := Path{KeySegment("a.b"), IndexSegment(3), KeySegment("7"), KeySegment("c/d")}
+
+	tests := []struct {
+		syntax PathSyntax
+		want   string
+	}{
+		{syntax: PathDots, want: `a\.b.3.\7.c/d`},
+		{syntax: PathBrackets, want: `a\.b[3].\7.c/d`},
+		{syntax: PathPointer, want: `/a.b/3/7/c~1d`},
+	}
+
+	for _, tt := range tests {
+		got := path.Format(tt.syntax)
+		if got != tt.want {
+			t.Errorf("Format(%d) = %s, want %s", tt.syntax, got, tt.want)
+		}
+
+		// JSON Pointers cannot tell a key of digits from an index.
+		if tt.syntax == PathPointer {
+			continue
+		}
+
+		if parsed := MustParsePath(got); !parsed.Equal(path) {
+			t.Errorf("ParsePath(%s) = %v, want %v", got, parsed, path)
+		}
+	}
+}
+
+func TestPathFormatRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	keys := []string{"", "/", ".", "[", "]", `\`, "~", "0", "12", "a", "/.x", "a[0]", "[1]", `\.`, "~1", " "}
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	for range 5000 {
+		path := make(Path, 1+rng.IntN(4))
+		for i := range path {
+			if rng.IntN(3) == 0 {
+				path[i] = IndexSegment(rng.IntN(12))
+			} else {
+				path[i] = KeySegment(keys[rng.IntN(len(keys))] + keys[rng.IntN(len(keys))])
+			}
+		}
+
+		for _, syntax := range []PathSyntax{PathDots, PathBrackets} {
+			formatted := path.Format(syntax)
+
+			parsed, err := ParsePath(formatted)
+			if err != nil {
+				t.Fatalf("ParsePath(%q) of %#v: %v", formatted, path, err)
+			}
+
+			if !parsed.Equal(path) {
+				t.Fatalf("ParsePath(%q) = %#v, want %#v", formatted, parsed, path)
+			}
+		}
+	}
+}
+
+func TestPathComposition(t *testing.T) {
+	t.Parallel()
+
+	path := Path{}.Child("a").Index(1).Child("b")
+
+	if got := path.String(); got != "a.1.b" {
+		t.Errorf("String() = %s, want a.1.b", got)
+	}
+
+	parent := path.Parent()
+	if !parent.Equal(MustParsePath("a[1]")) {
+		t.Errorf("Parent() = %v, want a.1", parent)
+	}
+
+	// Appending to a parent must not overwrite the original path.
+	_ = parent.Child("c")
+	if got := path.String(); got != "a.1.b" {
+		t.Errorf("path changed after appending to its parent: %s", got)
+	}
+
+	if !path.HasPrefix(parent) || parent.HasPrefix(path) || !path.HasPrefix(Path{}) {
+		t.Errorf("unexpected HasPrefix results")
+	}
+
+	ordered := []Path{
+		MustParsePath("a"), MustParsePath("a.0"), MustParsePath("a.1"), MustParsePath("a.b"), MustParsePath("b"),
+	}
+	for i := 1; i < len(ordered); i++ {
+		if ordered[i-1].Compare(ordered[i]) >= 0 || ordered[i].Compare(ordered[i-1]) <= 0 {
+			t.Errorf("expected %v < %v", ordered[i-1], ordered[i])
+		}
+	}
+}
+
+func TestGetWithPath(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":[{"b":"x"},{"c":[1,2]}],"0":{"1":true}}`)
+
+	tests := []struct {
+		path string
+		want any
+		ok   bool
+	}{
+		{path: "a.0.b", want: "x", ok: true},
+		{path: "a[1].c[1]", want: int64(2), ok: true},
+		{path: "/a/1/c/0", want: int64(1), ok: true},
+		{path: "/0/1", want: true, ok: true},
+		{path: "a.2", want: nil, ok: false},
+		{path: "a.0.b.c", want: nil, ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := m.Get(MustParsePath(tt.path))
+		if ok != tt.ok || !equalsAny(got, tt.want) {
+			t.Errorf("Get(%s) = %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	if s, err := m.GetString(MustParsePath("a[0].b")); err != nil || s != "x" {
+		t.Errorf("GetString() = %v, %v, want x", s, err)
+	}
+
+	if _, err := m.GetInt(MustParsePath("a.9")); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("GetInt() error = %v, want %v", err, ErrKeyNotFound)
+	}
+}
@@ -0,0 +1,103 @@
+package jsonchamp
+
+import (
+	"iter"
+	goSlices "slices"
+)
+
+// Set is an immutable set of strings.
+// It is stored in the same hash trie as Map, so versions of a set share their unchanged nodes,
+// and Union, Intersect and Difference combine two sets node by node.
+type Set struct {
+	root *bitmasked
+}
+
+// NewSet creates a new set with the given items.
+func NewSet(items ...string) *Set {
+	s := &Set{root: newRootNode()}
+
+	return s.Add(items...)
+}
+
+func (s *Set) key(item string) key {
+	return newKey(item, hashKey(nil, item))
+}
+
+// Add returns a new set with the items added.
+func (s *Set) Add(items ...string) *Set {
+	root := s.root
+
+	for _, item := range items {
+		k := s.key(item)
+		if _, ok := root.lookup(k); ok {
+			continue
+		}
+
+		root = root.setValue(&value{key: k, value: nil})
+	}
+
+	return &Set{root: root}
+}
+
+// Remove returns a new set without the item, and whether the item was in the set.
+func (s *Set) Remove(item string) (*Set, bool) {
+	newRoot, wasRemoved := s.root.delete(s.key(item))
+
+	return &Set{root: newRoot}, wasRemoved
+}
+
+// Contains returns true if the item is in the set.
+func (s *Set) Contains(item string) bool {
+	_, ok := s.root.lookup(s.key(item))
+
+	return ok
+}
+
+// Len returns the number of items in the set.
+func (s *Set) Len() int {
+	return s.root.size
+}
+
+// All returns an iterator over the items in the set, in no particular order.
+func (s *Set) All() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		s.root.all(func(v *value) bool {
+			return yield(v.key.key)
+		})
+	}
+}
+
+// Items returns the items in the set, sorted.
+func (s *Set) Items() []string {
+	return goSlices.Sorted(s.All())
+}
+
+// Equals returns true if both sets contain the same items.
+func (s *Set) Equals(other *Set) bool {
+	if s.Len() != other.Len() {
+		return false
+	}
+
+	for item := range s.All() {
+		if !other.Contains(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Union returns a set with the items that are in either set.
+func (s *Set) Union(other *Set) *Set {
+	return &Set{root: unionNodes(s.root, other.root, keepLeft)}
+}
+
+// Intersect returns a set with the items that are in both sets.
+func (s *Set) Intersect(other *Set) *Set {
+	return &Set{root: intersectNodes(s.root, other.root, keepLeft)}
+}
+
+// Difference returns a set with the items of the receiver that are not in the other set.
+func (s *Set) Difference(other *Set) *Set {
+	return &Set{root: differenceNodes(s.root, other.root)}
+}
@@ -0,0 +1,115 @@
+package jsonchamp
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestSetAddRemoveContains(t *testing.T) {
+	t.Parallel()
+
+	s := NewSet("a", "b", "a")
+	if s.Len() != 2 {
+		t.Fatalf("expected 2 items, got %d", s.Len())
+	}
+
+	if !s.Contains("a") || !s.Contains("b") || s.Contains("c") {
+		t.Errorf("unexpected contents: %v", s.Items())
+	}
+
+	removed, ok := s.Remove("a")
+	if !ok || removed.Contains("a") || removed.Len() != 1 {
+		t.Errorf("expected a to be removed, got %v", removed.Items())
+	}
+
+	if !s.Contains("a") {
+		t.Errorf("original set was modified")
+	}
+
+	if _, ok := removed.Remove("missing"); ok {
+		t.Errorf("expected removing a missing item to report false")
+	}
+
+	if got := s.Items(); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("Items() = %v", got)
+	}
+}
+
+func TestSetAlgebra(t *testing.T) {
+	t.Parallel()
+
+	var aItems, bItems []string
+
+	for i := range 2000 {
+		if i%2 == 0 {
+			aItems = append(aItems, fmt.Sprintf("item-%d", i))
+		}
+
+		if i%3 == 0 {
+			bItems = append(bItems, fmt.Sprintf("item-%d", i))
+		}
+	}
+
+	a, b := NewSet(aItems...), NewSet(bItems...)
+
+	tests := []struct {
+		name string
+		got  *Set
+		want func(i int) bool
+	}{
+		{name: "union", got: a.Union(b), want: func(i int) bool { return i%2 == 0 || i%3 == 0 }},
+		{name: "intersect", got: a.Intersect(b), want: func(i int) bool { return i%6 == 0 }},
+		{name: "difference", got: a.Difference(b), want: func(i int) bool { return i%2 == 0 && i%3 != 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			count := 0
+
+			for i := range 2000 {
+				item := fmt.Sprintf("item-%d", i)
+				if tt.got.Contains(item) != tt.want(i) {
+					t.Fatalf("unexpected membership of %s: %v", item, tt.got.Contains(item))
+				}
+
+				if tt.want(i) {
+					count++
+				}
+			}
+
+			if tt.got.Len() != count {
+				t.Errorf("expected %d items, got %d", count, tt.got.Len())
+			}
+
+			if len(slices.Collect(tt.got.All())) != count {
+				t.Errorf("expected iteration to yield %d items", count)
+			}
+		})
+	}
+}
+
+func TestSetAlgebraSharesNodes(t *testing.T) {
+	t.Parallel()
+
+	base := NewSet()
+	for i := range 5000 {
+		base = base.Add(fmt.Sprintf("item-%d", i))
+	}
+
+	changed := base.Add("extra")
+
+	if base.Union(base).root != base.root || base.Intersect(base).root != base.root {
+		t.Errorf("expected operations on the same set to return it unchanged")
+	}
+
+	if got := changed.Difference(base); !got.Equals(NewSet("extra")) {
+		t.Errorf("Difference() = %v, want [extra]", got.Items())
+	}
+
+	if got := changed.Intersect(base); !got.Equals(base) {
+		t.Errorf("expected intersection with the base set to equal it")
+	}
+}
@@ -138,6 +138,13 @@ func equalsAny(a any, b any) bool {
 		}
 
 		return v.Equals(bTyped)
+	case *List:
+		bList, ok := b.(*List)
+		if !ok {
+			return false
+		}
+
+		return v.Equals(bList)
 	case []any:
 		aSlice, ok := a.([]any)
 		if !ok {
@@ -150,6 +157,13 @@ func equalsAny(a any, b any) bool {
 		}
 
 		return equalsAnyList(aSlice, bSlice)
+	case map[string]any:
+		bMap, ok := b.(map[string]any)
+		if !ok {
+			return false
+		}
+
+		return maps.EqualFunc(v, bMap, equalsAny)
 	case []string:
 		aSlice, ok := a.([]string)
 		if !ok {
@@ -0,0 +1,208 @@
+package jsonchamp
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Txn is an optimistic transaction on the map held by an Atom.
+// Reads see the snapshot taken by Begin and the transaction's own writes. Writes are buffered until Commit,
+// which applies them to the latest version if none of the paths read by the transaction have changed.
+// A Txn is not safe for concurrent use.
+type Txn struct {
+	atom     *Atom
+	snapshot *Map
+	// working is the snapshot with the buffered writes applied.
+	working *Map
+	reads   []Path
+	writes  []txnWrite
+}
+
+type txnWrite struct {
+	path   Path
+	value  any
+	delete bool
+}
+
+// Begin starts a transaction on the current version of the atom.
+func (a *Atom) Begin() *Txn {
+	snapshot := a.Load()
+
+	return &Txn{atom: a, snapshot: snapshot, working: snapshot, reads: nil, writes: nil}
+}
+
+// Transact runs fn in a transaction and commits it, running fn again with a new transaction on conflicts.
+// If fn returns an error, the transaction is abandoned and the error is returned.
+func (a *Atom) Transact(fn func(txn *Txn) error) error {
+	for {
+		txn := a.Begin()
+		if err := fn(txn); err != nil {
+			return err
+		}
+
+		err := txn.Commit()
+		if !errors.Is(err, ErrConflict) {
+			return err
+		}
+	}
+}
+
+// Snapshot returns the version the transaction started from.
+func (t *Txn) Snapshot() *Map {
+	return t.snapshot
+}
+
+// Get returns the value at the path and records the path as read.
+func (t *Txn) Get(path Path) (any, bool) {
+	if !t.wrote(path) {
+		t.reads = append(t.reads, path)
+	}
+
+	return t.working.Get(path)
+}
+
+// wrote returns true if the path is at or below a path written by the transaction.
+// Such reads see the transaction's own writes, so they cannot conflict.
+func (t *Txn) wrote(path Path) bool {
+	for _, w := range t.writes {
+		if path.HasPrefix(w.path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Set buffers setting the value at the path. Missing maps on the path are created.
+// It returns ErrInvalidPath if the path goes through a value that is not a map or array,
+// or an array index that does not exist.
+func (t *Txn) Set(path Path, value any) error {
+	write := txnWrite{path: path, value: normalizeValue(value), delete: false}
+
+	working, err := write.apply(t.working)
+	if err != nil {
+		return err
+	}
+
+	t.working = working
+	t.writes = append(t.writes, write)
+
+	return nil
+}
+
+// Delete buffers deleting the value at the path. Deleting a missing value is not an error.
+func (t *Txn) Delete(path Path) error {
+	write := txnWrite{path: path, value: nil, delete: true}
+
+	working, err := write.apply(t.working)
+	if err != nil {
+		return err
+	}
+
+	t.working = working
+	t.writes = append(t.writes, write)
+
+	return nil
+}
+
+func (w txnWrite) apply(m *Map) (*Map, error) {
+	if len(w.path) == 0 {
+		return nil, fmt.Errorf("%w: empty path", ErrInvalidPath)
+	}
+
+	if w.delete {
+		parent, ok := getPath(m, w.path.Parent())
+		if !ok {
+			return m, nil
+		}
+
+		updated, ok := setPath(m, w.path.Parent(), withoutChildAt(parent, w.path[len(w.path)-1]))
+		if !ok {
+			return m, nil
+		}
+
+		updatedMap, _ := updated.(*Map)
+
+		return updatedMap, nil
+	}
+
+	updated, ok := setPath(m, w.path, w.value)
+	if !ok {
+		return nil, fmt.Errorf("%w: cannot set '%s'", ErrInvalidPath, w.path)
+	}
+
+	updatedMap, _ := updated.(*Map)
+
+	return updatedMap, nil
+}
+
+// withoutChildAt removes the child at the segment from a map or list, if it exists.
+func withoutChildAt(container any, segment PathSegment) any {
+	if _, ok := childAt(container, segment); !ok {
+		return container
+	}
+
+	if _, ok := container.(*Map); ok {
+		return withoutChild(container, KeySegment(segment.String()))
+	}
+
+	index := segment.Index
+	if !segment.IsIndex {
+		index, _ = parseIndex(segment.Key)
+	}
+
+	return withoutChild(container, IndexSegment(index))
+}
+
+// Commit applies the buffered writes to the latest version of the atom.
+// After a successful commit, the transaction continues from the committed version.
+// If another writer changed a value the transaction read since the snapshot, it returns ErrConflict
+// and the atom is left unchanged. Writes to paths that were not read are applied to the latest version,
+// so transactions that touch disjoint paths all succeed.
+func (t *Txn) Commit() error {
+	if len(t.writes) == 0 {
+		return t.validate(t.atom.Load())
+	}
+
+	for {
+		latest := t.atom.Load()
+		if err := t.validate(latest); err != nil {
+			return err
+		}
+
+		updated := latest
+
+		for _, w := range t.writes {
+			var err error
+			if updated, err = w.apply(updated); err != nil {
+				return fmt.Errorf("%w: %w", ErrConflict, err)
+			}
+		}
+
+		if t.atom.CompareAndSwap(latest, updated) {
+			t.snapshot, t.working = updated, updated
+			t.reads, t.writes = nil, nil
+
+			return nil
+		}
+	}
+}
+
+// validate checks that the values at the read paths are the same in the latest version as in the snapshot.
+// Unchanged values are usually the same nodes, so most paths are checked without comparing their contents.
+func (t *Txn) validate(latest *Map) error {
+	if latest == t.snapshot {
+		return nil
+	}
+
+	for _, path := range t.reads {
+		before, existedBefore := getPath(t.snapshot, path)
+		after, existsAfter := getPath(latest, path)
+
+		if existedBefore != existsAfter || (existedBefore && !sameInstance(before, after) && !equalsAny(before, after)) {
+			return fmt.Errorf("%w: '%s' changed", ErrConflict, path)
+		}
+	}
+
+	return nil
+}
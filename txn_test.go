@@ -0,0 +1,219 @@
+package jsonchamp
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTxnDisjointWritesBothSucceed(t *testing.T) {
+	t.Parallel()
+
+	a := NewAtom(mustParse(t, `{"a":{"n":1},"b":{"n":1}}`))
+
+	first, second := a.Begin(), a.Begin()
+
+	n, _ := first.Get(MustParsePath("a.n"))
+	if err := first.Set(MustParsePath("a.n"), n.(int64)+1); err != nil {
+		t.Fatal(err)
+	}
+
+	n, _ = second.Get(MustParsePath("b.n"))
+	if err := second.Set(MustParsePath("b.n"), n.(int64)+1); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := first.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := second.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := mustParse(t, `{"a":{"n":2},"b":{"n":2}}`); !a.Load().Equals(want) {
+		t.Errorf("Load() = %v, want %v", a.Load().ToMap(), want.ToMap())
+	}
+}
+
+func TestTxnConflict(t *testing.T) {
+	t.Parallel()
+
+	a := NewAtom(mustParse(t, `{"a":{"n":1}}`))
+
+	txn := a.Begin()
+	txn.Get(MustParsePath("a"))
+
+	if err := txn.Set(MustParsePath("b"), true); err != nil {
+		t.Fatal(err)
+	}
+
+	a.Swap(func(m *Map) *Map { return m.Set("a", NewFromItems("n", 2)) })
+
+	before := a.Load()
+
+	if err := txn.Commit(); !errors.Is(err, ErrConflict) {
+		t.Fatalf("Commit() error = %v, want %v", err, ErrConflict)
+	}
+
+	if a.Load() != before {
+		t.Errorf("expected a failed commit to leave the atom unchanged")
+	}
+}
+
+func TestTxnReadsOwnWrites(t *testing.T) {
+	t.Parallel()
+
+	a := NewAtom(mustParse(t, `{"list":[1,2,3],"gone":true}`))
+
+	txn := a.Begin()
+
+	if err := txn.Set(MustParsePath("x.y"), "z"); err != nil {
+		t.Fatal(err)
+	}
+
+	if v, ok := txn.Get(MustParsePath("x.y")); !ok || v != "z" {
+		t.Errorf("Get() = %v, %v, want z", v, ok)
+	}
+
+	if err := txn.Delete(MustParsePath("list.0")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := txn.Delete(MustParsePath("gone")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := txn.Set(MustParsePath("list.5"), 1); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Set() error = %v, want %v", err, ErrInvalidPath)
+	}
+
+	// A concurrent change to a path that was only written does not conflict.
+	a.Swap(func(m *Map) *Map { return m.Set("x", NewFromItems("other", 1)) })
+
+	if err := txn.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := mustParse(t, `{"list":[2,3],"x":{"other":1,"y":"z"}}`); !a.Load().Equals(want) {
+		t.Errorf("Load() = %v, want %v", a.Load().ToMap(), want.ToMap())
+	}
+}
+
+func TestTransactRetries(t *testing.T) {
+	t.Parallel()
+
+	a := NewAtom(NewFromItems("count", 0))
+	path := MustParsePath("count")
+
+	const goroutines, increments = 8, 100
+
+	var wg sync.WaitGroup
+
+	for range goroutines {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for range increments {
+				err := a.Transact(func(txn *Txn) error {
+					count, _ := txn.Get(path)
+
+					return txn.Set(path, count.(int64)+1)
+				})
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if count, _ := a.Load().GetInt("count"); count != goroutines*increments {
+		t.Errorf("count = %d, want %d", count, goroutines*increments)
+	}
+}
+
+func TestTransactLargeNestedMap(t *testing.T) {
+	t.Parallel()
+
+	// Both the root and the nested maps have more keys than a small node, so updates copy shared trie nodes.
+	const groups, counters = smallMaxSize * 2, smallMaxSize * 2
+
+	initial := New()
+	for g := range groups {
+		group := New()
+		for c := range counters {
+			group = group.Set(fmt.Sprintf("counter%d", c), 0)
+		}
+
+		initial = initial.Set(fmt.Sprintf("group%d", g), group)
+	}
+
+	a := NewAtom(initial)
+
+	const goroutines, increments = 8, 100
+
+	var wg sync.WaitGroup
+
+	for g := range goroutines {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range increments {
+				n := g*increments + i
+				path := MustParsePath(fmt.Sprintf("group%d.counter%d", n%groups, n/groups%counters))
+				scratch := MustParsePath(fmt.Sprintf("group%d.scratch%d", n%groups, g))
+
+				err := a.Transact(func(txn *Txn) error {
+					count, _ := txn.Get(path)
+					if err := txn.Set(path, count.(int64)+1); err != nil {
+						return err
+					}
+
+					if err := txn.Set(scratch, i); err != nil {
+						return err
+					}
+
+					return txn.Delete(scratch)
+				})
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	sum := func(m *Map) int64 {
+		total := int64(0)
+
+		for _, groupKey := range m.Keys() {
+			group, _ := m.GetMap(groupKey)
+			if group.Len() != counters {
+				t.Errorf("%s has %d keys, want %d", groupKey, group.Len(), counters)
+			}
+
+			for _, counterKey := range group.Keys() {
+				count, _ := group.GetInt(counterKey)
+				total += count
+			}
+		}
+
+		return total
+	}
+
+	if total := sum(a.Load()); total != goroutines*increments {
+		t.Errorf("total = %d, want %d", total, goroutines*increments)
+	}
+
+	if total := sum(initial); total != 0 {
+		t.Errorf("total of the initial map = %d, want 0", total)
+	}
+}
@@ -0,0 +1,172 @@
+package jsonchamp
+
+import (
+	"cmp"
+	"iter"
+	goSlices "slices"
+)
+
+// TypedMap is an immutable hash map with values of a single type.
+// It shares the trie implementation with Map, but stores values as they are instead of normalizing them
+// to JSON types, so it suits caches of arbitrary Go values.
+type TypedMap[V any] struct {
+	root   *bitmasked
+	hasher *keyHasher
+}
+
+// NewTyped creates a new typed map.
+func NewTyped[V any](opts ...MapOption) *TypedMap[V] {
+	options := defaultMapOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &TypedMap[V]{root: newRootNode(), hasher: newKeyHasher(options)}
+}
+
+func (m *TypedMap[V]) key(k string) key {
+	return newKey(k, hashKey(m.hasher, k))
+}
+
+// Get retrieves the value of a key.
+func (m *TypedMap[V]) Get(key string) (V, bool) {
+	v, ok := m.root.lookup(m.key(key))
+	if !ok {
+		var zero V
+
+		return zero, false
+	}
+
+	return typedValue[V](v), true
+}
+
+func typedValue[V any](v *value) V {
+	typed, ok := v.value.(V)
+	if !ok {
+		// Only nil interface values fail the assertion, since all values are set through Set.
+		var zero V
+
+		return zero
+	}
+
+	return typed
+}
+
+// Set returns a new map with the key set to the value.
+func (m *TypedMap[V]) Set(key string, value V) *TypedMap[V] {
+	newRoot, ok := m.root.set(m.key(key), value).(*bitmasked)
+	if !ok {
+		panic("expected bitmasked")
+	}
+
+	return &TypedMap[V]{root: newRoot, hasher: m.hasher}
+}
+
+// Delete returns a new map without the key, and whether the key was in the map.
+func (m *TypedMap[V]) Delete(key string) (*TypedMap[V], bool) {
+	newRoot, wasDeleted := m.root.delete(m.key(key))
+
+	return &TypedMap[V]{root: newRoot, hasher: m.hasher}, wasDeleted
+}
+
+// Contains returns true if the key exists in the map.
+func (m *TypedMap[V]) Contains(key string) bool {
+	_, ok := m.root.lookup(m.key(key))
+
+	return ok
+}
+
+// Len returns the number of keys in the map.
+func (m *TypedMap[V]) Len() int {
+	return m.root.size
+}
+
+// Keys returns a list of all keys in the map.
+func (m *TypedMap[V]) Keys() []string {
+	return m.root.keys()
+}
+
+// All returns an iterator over the keys and values of the map, in no particular order.
+func (m *TypedMap[V]) All() iter.Seq2[string, V] {
+	return func(yield func(string, V) bool) {
+		m.root.all(func(v *value) bool {
+			return yield(v.key.key, typedValue[V](v))
+		})
+	}
+}
+
+// TypedChange is a difference of a single key between two typed maps.
+type TypedChange[V any] struct {
+	Key  string
+	Kind ChangeKind
+	// Old is the value in the receiver. It is the zero value for added keys.
+	Old V
+	// New is the value in the other map. It is the zero value for removed keys.
+	New V
+}
+
+// Diff returns the keys that were added, removed or modified in other, ordered by key.
+// Values are compared with equal. If equal is nil, a value counts as modified when it was set again,
+// even to an equal value.
+// Maps derived from each other are compared node by node, skipping the nodes they share.
+func (m *TypedMap[V]) Diff(other *TypedMap[V], equal func(a V, b V) bool) []TypedChange[V] {
+	var changes []TypedChange[V]
+
+	onChange := func(a *value, b *value) {
+		var zero V
+
+		switch {
+		case a == nil:
+			changes = append(changes, TypedChange[V]{Key: b.key.key, Kind: ChangeAdded, Old: zero, New: typedValue[V](b)})
+		case b == nil:
+			changes = append(changes, TypedChange[V]{Key: a.key.key, Kind: ChangeRemoved, Old: typedValue[V](a), New: zero})
+		default:
+			oldValue, newValue := typedValue[V](a), typedValue[V](b)
+			if equal == nil || !equal(oldValue, newValue) {
+				changes = append(changes, TypedChange[V]{Key: a.key.key, Kind: ChangeModified, Old: oldValue, New: newValue})
+			}
+		}
+	}
+
+	if sameHasher(m.hasher, other.hasher) {
+		diffNodes(m.root, other.root, onChange)
+	} else {
+		diffByKey(m.root, other.root, m.key, other.key, onChange)
+	}
+
+	goSlices.SortFunc(changes, func(a TypedChange[V], b TypedChange[V]) int {
+		return cmp.Compare(a.Key, b.Key)
+	})
+
+	return changes
+}
+
+// diffByKey compares two tries with different hashers key by key.
+func diffByKey(
+	a *bitmasked,
+	b *bitmasked,
+	aKey func(string) key,
+	bKey func(string) key,
+	onChange func(*value, *value),
+) {
+	a.all(func(aValue *value) bool {
+		bValue, ok := b.lookup(bKey(aValue.key.key))
+
+		switch {
+		case !ok:
+			onChange(aValue, nil)
+		case aValue != bValue:
+			onChange(aValue, bValue)
+		}
+
+		return true
+	})
+
+	b.all(func(bValue *value) bool {
+		if _, ok := a.lookup(aKey(bValue.key.key)); !ok {
+			onChange(nil, bValue)
+		}
+
+		return true
+	})
+}
@@ -0,0 +1,140 @@
+package jsonchamp
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"testing"
+)
+
+type typedTestValue struct {
+	Name  string
+	Count int
+}
+
+func TestTypedMapSetGetDelete(t *testing.T) {
+	t.Parallel()
+
+	m := NewTyped[typedTestValue]()
+	m1 := m.Set("a", typedTestValue{Name: "a", Count: 1})
+	m2 := m1.Set("b", typedTestValue{Name: "b", Count: 2})
+
+	if m.Len() != 0 || m1.Len() != 1 || m2.Len() != 2 {
+		t.Fatalf("unexpected lengths: %d, %d, %d", m.Len(), m1.Len(), m2.Len())
+	}
+
+	if v, ok := m2.Get("b"); !ok || v.Count != 2 {
+		t.Errorf("Get(b) = %v, %v, want count 2", v, ok)
+	}
+
+	if _, ok := m1.Get("b"); ok {
+		t.Errorf("expected previous version not to contain b")
+	}
+
+	deleted, ok := m2.Delete("a")
+	if !ok || deleted.Contains("a") || !m2.Contains("a") {
+		t.Errorf("expected a to be deleted from the new version only")
+	}
+
+	if _, ok := deleted.Delete("missing"); ok {
+		t.Errorf("expected deleting a missing key to report false")
+	}
+
+	keys := m2.Keys()
+	slices.Sort(keys)
+
+	if !slices.Equal(keys, []string{"a", "b"}) {
+		t.Errorf("Keys() = %v", keys)
+	}
+}
+
+func TestTypedMapStoresValuesAsIs(t *testing.T) {
+	t.Parallel()
+
+	values := []int{1, 2, 3}
+	m := NewTyped[[]int]().Set("a", values)
+
+	got, _ := m.Get("a")
+	if &got[0] != &values[0] {
+		t.Errorf("expected the slice to be stored without copying")
+	}
+
+	pointers := NewTyped[*typedTestValue]().Set("nil", nil)
+	if v, ok := pointers.Get("nil"); !ok || v != nil {
+		t.Errorf("Get(nil) = %v, %v, want nil, true", v, ok)
+	}
+}
+
+func TestTypedMapAll(t *testing.T) {
+	t.Parallel()
+
+	m := NewTyped[int]()
+	for i := range 500 {
+		m = m.Set(fmt.Sprintf("key-%d", i), i)
+	}
+
+	sum := 0
+	for key, v := range m.All() {
+		if key != fmt.Sprintf("key-%d", v) {
+			t.Fatalf("unexpected entry %s: %d", key, v)
+		}
+
+		sum += v
+	}
+
+	if want := 499 * 500 / 2; sum != want {
+		t.Errorf("sum = %d, want %d", sum, want)
+	}
+}
+
+func TestTypedMapDiff(t *testing.T) {
+	t.Parallel()
+
+	base := NewTyped[int]()
+	for i := range 1000 {
+		base = base.Set(fmt.Sprintf("key-%d", i), i)
+	}
+
+	changed := base.Set("key-1", 100).Set("key-2", 2).Set("added", 1)
+	changed, _ = changed.Delete("key-3")
+
+	equal := func(a int, b int) bool { return a == b }
+
+	want := []TypedChange[int]{
+		{Key: "added", Kind: ChangeAdded, Old: 0, New: 1},
+		{Key: "key-1", Kind: ChangeModified, Old: 1, New: 100},
+		{Key: "key-3", Kind: ChangeRemoved, Old: 3, New: 0},
+	}
+
+	otherHasher := NewTyped[int](WithHasher(fnv.New64a))
+	for key, v := range changed.All() {
+		otherHasher = otherHasher.Set(key, v)
+	}
+
+	tests := []struct {
+		name  string
+		other *TypedMap[int]
+	}{
+		{name: "derived", other: changed},
+		{name: "different hasher", other: otherHasher},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := base.Diff(tt.other, equal); !slices.Equal(got, want) {
+				t.Errorf("Diff() = %v, want %v", got, want)
+			}
+		})
+	}
+
+	if got := base.Diff(base, nil); len(got) != 0 {
+		t.Errorf("expected no changes against itself, got %v", got)
+	}
+
+	// Without an equal function, setting a key again counts as a modification.
+	if got := base.Diff(base.Set("key-2", 2), nil); len(got) != 1 || got[0].Key != "key-2" {
+		t.Errorf("Diff() = %v, want key-2 modified", got)
+	}
+}
@@ -0,0 +1,151 @@
+package jsonchamp
+
+import (
+	goSlices "slices"
+)
+
+// WalkAction tells Walk how to continue after visiting a value.
+type WalkAction int
+
+const (
+	// WalkContinue visits the children of the value, if it is a map or an array, and then the next value.
+	WalkContinue WalkAction = iota
+	// WalkSkip does not visit the children of the value.
+	WalkSkip
+	// WalkStop ends the walk.
+	WalkStop
+)
+
+// Walk visits every value in a map depth first, including maps and arrays before their children.
+// The keys of each map are visited in sorted order, and array elements in index order.
+func Walk(m *Map, visit func(path Path, v any) WalkAction) {
+	walkMap(nil, m, visit)
+}
+
+// walkMap returns false if the walk was stopped.
+func walkMap(path Path, m *Map, visit func(path Path, v any) WalkAction) bool {
+	for _, key := range goSlices.Sorted(goSlices.Values(m.Keys())) {
+		v, _ := m.Get(key)
+		if !walkValue(path.Child(key), v, visit) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func walkValue(path Path, v any, visit func(path Path, v any) WalkAction) bool {
+	switch visit(path, v) {
+	case WalkStop:
+		return false
+	case WalkSkip:
+		return true
+	case WalkContinue:
+	}
+
+	switch t := v.(type) {
+	case *Map:
+		return walkMap(path, t, visit)
+	case *List:
+		for i, element := range t.All() {
+			if !walkValue(path.Index(i), element, visit) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// TransformAction tells Transform what to do with a visited value.
+type TransformAction int
+
+const (
+	// TransformKeep keeps the value and transforms its children, if it is a map or an array.
+	TransformKeep TransformAction = iota
+	// TransformSkip keeps the value without visiting its children.
+	TransformSkip
+	// TransformReplace replaces the value with the returned value. The replacement is not visited.
+	TransformReplace
+	// TransformDelete removes the value from its map or array.
+	TransformDelete
+)
+
+// Transform returns a new map where fn has been applied to every value, in the same order as Walk.
+// The paths passed to fn refer to the original map, so deleting an array element does not shift the paths
+// of the following elements. Maps and arrays without changes are shared with the original map,
+// and if nothing changes the original map is returned.
+func Transform(m *Map, fn func(path Path, v any) (any, TransformAction)) *Map {
+	return transformMap(nil, m, fn)
+}
+
+func transformMap(path Path, m *Map, fn func(path Path, v any) (any, TransformAction)) *Map {
+	result := m
+
+	for _, key := range goSlices.Sorted(goSlices.Values(m.Keys())) {
+		v, _ := m.Get(key)
+
+		transformed, keep := transformValue(path.Child(key), v, fn)
+
+		switch {
+		case !keep:
+			result, _ = result.Delete(key)
+		case !sameInstance(transformed, v):
+			result = result.Set(key, transformed)
+		}
+	}
+
+	return result
+}
+
+// transformValue returns the transformed value, and false if it was deleted.
+func transformValue(path Path, v any, fn func(path Path, v any) (any, TransformAction)) (any, bool) {
+	replacement, action := fn(path, v)
+
+	switch action {
+	case TransformDelete:
+		return nil, false
+	case TransformReplace:
+		return normalizeValue(replacement), true
+	case TransformSkip:
+		return v, true
+	case TransformKeep:
+	}
+
+	switch t := v.(type) {
+	case *Map:
+		return transformMap(path, t, fn), true
+	case *List:
+		return transformList(path, t, fn), true
+	default:
+		return v, true
+	}
+}
+
+func transformList(path Path, l *List, fn func(path Path, v any) (any, TransformAction)) *List {
+	result := l
+	kept := make([]any, 0, l.Len())
+	deleted := false
+
+	for i, element := range l.All() {
+		transformed, keep := transformValue(path.Index(i), element, fn)
+		if !keep {
+			deleted = true
+
+			continue
+		}
+
+		kept = append(kept, transformed)
+
+		if !sameInstance(transformed, element) {
+			result = result.Set(i, transformed)
+		}
+	}
+
+	// Deleting elements shifts the rest of the list, so it is rebuilt.
+	if deleted {
+		return newListFromValues(kept)
+	}
+
+	return result
+}
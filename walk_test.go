@@ -0,0 +1,115 @@
+package jsonchamp
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"b":{"c":1,"skip":{"x":1}},"a":[1,{"d":2}],"z":3}`)
+
+	tests := []struct {
+		name  string
+		stop  string
+		paths []string
+	}{
+		{
+			name:  "all",
+			stop:  "",
+			paths: []string{"a", "a.0", "a.1", "a.1.d", "b", "b.c", "b.skip", "z"},
+		},
+		{
+			name:  "stop",
+			stop:  "b.c",
+			paths: []string{"a", "a.0", "a.1", "a.1.d", "b", "b.c"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var paths []string
+
+			Walk(m, func(path Path, _ any) WalkAction {
+				paths = append(paths, path.String())
+
+				switch path.String() {
+				case tt.stop:
+					return WalkStop
+				case "b.skip":
+					return WalkSkip
+				default:
+					return WalkContinue
+				}
+			})
+
+			if !slices.Equal(paths, tt.paths) {
+				t.Errorf("visited %v, want %v", paths, tt.paths)
+			}
+		})
+	}
+}
+
+func TestWalkPathSegments(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"a":[{"0":true}]}`)
+
+	var leaf Path
+
+	Walk(m, func(path Path, v any) WalkAction {
+		if v == true {
+			leaf = path
+		}
+
+		return WalkContinue
+	})
+
+	want := Path{KeySegment("a"), IndexSegment(0), KeySegment("0")}
+	if !slices.Equal(leaf, want) {
+		t.Errorf("path = %v, want %v", leaf, want)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	t.Parallel()
+
+	m := mustParse(t, `{"user":{"name":"a","password":"secret"},"tags":["x","drop","y"],"kept":{"n":1}}`)
+
+	got := Transform(m, func(path Path, v any) (any, TransformAction) {
+		switch {
+		case path.String() == "user.password":
+			return "***", TransformReplace
+		case v == "drop":
+			return nil, TransformDelete
+		case path.String() == "kept":
+			return nil, TransformSkip
+		default:
+			return nil, TransformKeep
+		}
+	})
+
+	want := mustParse(t, `{"user":{"name":"a","password":"***"},"tags":["x","y"],"kept":{"n":1}}`)
+	if !got.Equals(want) {
+		t.Errorf("Transform() = %v, want %v", got.ToMap(), want.ToMap())
+	}
+
+	before, _ := m.GetMap("kept")
+	after, _ := got.GetMap("kept")
+
+	if before != after {
+		t.Errorf("expected unchanged nested map to be shared")
+	}
+
+	if password, _ := m.Get([]string{"user", "password"}); password != "secret" {
+		t.Errorf("original map was modified: password = %v", password)
+	}
+
+	unchanged := Transform(m, func(Path, any) (any, TransformAction) { return nil, TransformKeep })
+	if unchanged != m {
+		t.Errorf("expected Transform to return the original map when nothing changes")
+	}
+}